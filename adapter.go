@@ -29,13 +29,14 @@ import (
 	"gorm.io/driver/mysql"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"gorm.io/gorm/logger"
-	"gorm.io/plugin/dbresolver"
 )
 
 const (
-	defaultDatabaseName = "casbin"
-	defaultTableName    = "casbin_rule"
+	defaultDatabaseName      = "casbin"
+	defaultTableName         = "casbin_rule"
+	defaultFilterParallelism = 4
 )
 
 const customTableKey = "customTableKey"
@@ -75,15 +76,29 @@ type BatchFilter struct {
 
 // Adapter represents the Gorm adapter for policy storage.
 type Adapter struct {
-	driverName     string
-	dataSourceName string
-	databaseName   string
-	tablePrefix    string
-	tableName      string
-	dbSpecified    bool
-	db             *gorm.DB
-	isFiltered     bool
-	customTableKey interface{}
+	driverName        string
+	dataSourceName    string
+	databaseName      string
+	schema            string
+	tablePrefix       string
+	tableName         string
+	dbSpecified       bool
+	db                *gorm.DB
+	isFiltered        bool
+	customTableKey    interface{}
+	autoMigrate       bool
+	tenantColumn      string
+	tenantResolver    TenantResolver
+	replicas          []gorm.Dialector
+	writeTracker      *writeTracker
+	loadPageSize      int
+	filterParallelism int
+	pruneDeclared     bool
+	sourceName        string
+	loadSources       []string
+	validity          bool
+	softDelete        bool
+	dryRun            *dryRunRecorder
 }
 
 // finalizer is the destructor for Adapter.
@@ -98,22 +113,34 @@ func finalizer(a *Adapter) {
 	}
 }
 
-// Select conn according to table name（use map store name-index）
-type specificPolicy int
-
-func (p *specificPolicy) Resolve(connPools []gorm.ConnPool) gorm.ConnPool {
-	return connPools[*p]
+// DbPool holds one gorm instance per named database.
+// Every adapter created from the pool is pinned to the instance of its database,
+// so adapters of different databases can be used concurrently.
+type DbPool struct {
+	dbMap map[string]*gorm.DB
 }
 
-type DbPool struct {
-	dbMap  map[string]specificPolicy
-	policy *specificPolicy
-	source *gorm.DB
+func (dbPool DbPool) switchDb(dbName string) (*gorm.DB, error) {
+	db, ok := dbPool.dbMap[dbName]
+	if !ok {
+		return nil, fmt.Errorf("database %q is not in the db pool", dbName)
+	}
+	return db.Session(&gorm.Session{}), nil
 }
 
-func (dbPool *DbPool) switchDb(dbName string) *gorm.DB {
-	*dbPool.policy = dbPool.dbMap[dbName]
-	return dbPool.source.Clauses(dbresolver.Write)
+// Close closes the connections of all databases in the pool.
+func (dbPool DbPool) Close() error {
+	var errs []error
+	for _, db := range dbPool.dbMap {
+		sqlDB, err := db.DB()
+		if err == nil {
+			err = sqlDB.Close()
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
 }
 
 // NewAdapter is the constructor for Adapter.
@@ -178,6 +205,10 @@ func NewAdapter(driverName string, dataSourceName string, params ...interface{})
 		return nil, errors.New("too many parameters")
 	}
 
+	if err := validateTableName(a.getFullTableName()); err != nil {
+		return nil, err
+	}
+
 	// Open the DB, create it if not existed.
 	err := a.Open()
 	if err != nil {
@@ -203,6 +234,9 @@ func NewAdapterByDBUseTableName(db *gorm.DB, prefix string, tableName string, cu
 		customTableKey: customTableKey,
 		db:             db,
 	}
+	if err := validateTableName(a.getFullTableName()); err != nil {
+		return nil, err
+	}
 
 	if len(autoMigrate) > 0 && autoMigrate[0] {
 		err := a.createTable()
@@ -221,25 +255,35 @@ func NewAdapterByDBUseTableName(db *gorm.DB, prefix string, tableName string, cu
 // a = initAdapterWithGormInstanceByMulDb(t,dbPool,"casbin2","","casbin_rule2")/*
 func InitDbResolver(dbArr []gorm.Dialector, dbNames []string) (DbPool, error) {
 	if len(dbArr) == 0 {
-		panic("dbArr len is 0")
+		return DbPool{}, errors.New("dbArr len is 0")
 	}
-	source, e := gorm.Open(dbArr[0])
-	if e != nil {
-		panic(e.Error())
+	if len(dbArr) != len(dbNames) {
+		return DbPool{}, fmt.Errorf("got %d dialectors for %d db names", len(dbArr), len(dbNames))
 	}
-	var p specificPolicy
-	p = 0
-	err := source.Use(dbresolver.Register(dbresolver.Config{Policy: &p, Sources: dbArr}))
-	dbMap := make(map[string]specificPolicy)
-	for i := 0; i < len(dbNames); i++ {
-		dbMap[dbNames[i]] = specificPolicy(i)
+
+	dbPool := DbPool{dbMap: make(map[string]*gorm.DB, len(dbNames))}
+	for i, dialector := range dbArr {
+		if _, ok := dbPool.dbMap[dbNames[i]]; ok {
+			_ = dbPool.Close()
+			return DbPool{}, fmt.Errorf("duplicate db name %q", dbNames[i])
+		}
+		db, err := gorm.Open(dialector)
+		if err != nil {
+			_ = dbPool.Close()
+			return DbPool{}, err
+		}
+		dbPool.dbMap[dbNames[i]] = db
 	}
-	return DbPool{dbMap: dbMap, policy: &p, source: source}, err
+	return dbPool, nil
 }
 
+// NewAdapterByMulDb creates gorm-adapter on the database named dbName of the db pool.
 func NewAdapterByMulDb(dbPool DbPool, dbName string, prefix string, tableName string, autoMigrate ...bool) (*Adapter, error) {
 	//change DB
-	db := dbPool.switchDb(dbName)
+	db, err := dbPool.switchDb(dbName)
+	if err != nil {
+		return nil, err
+	}
 
 	return NewAdapterByDBUseTableName(db, prefix, tableName, nil, autoMigrate...)
 }
@@ -258,11 +302,18 @@ func NewFilteredAdapter(driverName string, dataSourceName string, params ...inte
 // NewFilteredAdapterByDB is the constructor for FilteredAdapter.
 // Casbin will not automatically call LoadPolicy() for a filtered adapter.
 func NewFilteredAdapterByDB(db *gorm.DB, prefix string, tableName string) (*Adapter, error) {
+	if len(tableName) == 0 {
+		tableName = defaultTableName
+	}
+
 	adapter := &Adapter{
 		tablePrefix: prefix,
 		tableName:   tableName,
 		isFiltered:  true,
 	}
+	if err := validateTableName(adapter.getFullTableName()); err != nil {
+		return nil, err
+	}
 	adapter.db = db.Session(&gorm.Session{Context: db.Statement.Context})
 
 	return adapter, nil
@@ -367,40 +418,69 @@ func (a *Adapter) getTableInstance() *CasbinRule {
 }
 
 func (a *Adapter) getFullTableName() string {
+	tableName := a.tableName
 	if a.tablePrefix != "" {
-		return a.tablePrefix + "_" + a.tableName
+		tableName = a.tablePrefix + "_" + tableName
+	}
+	if a.schema != "" {
+		tableName = a.schema + "." + tableName
 	}
-	return a.tableName
+	return tableName
 }
 
 func (a *Adapter) casbinRuleTable() func(db *gorm.DB) *gorm.DB {
 	return func(db *gorm.DB) *gorm.DB {
 		tableName := a.getFullTableName()
-		return db.Table(tableName)
+		return a.liveScope(a.tenantScope(db.Table(tableName)))
 	}
 }
 
 func (a *Adapter) createTable() error {
 	if a.customTableKey != nil {
-		return a.db.AutoMigrate(a.customTableKey)
+		if err := a.db.AutoMigrate(a.customTableKey); err != nil {
+			return err
+		}
+		return a.createOptionalColumns()
 	}
 
-	t := a.getTableInstance()
-	if err := a.db.AutoMigrate(t); err != nil {
+	if err := a.createSchema(); err != nil {
 		return err
 	}
 
+	t := a.getTableInstance()
 	tableName := a.getFullTableName()
-	index := strings.ReplaceAll("idx_"+tableName, ".", "_")
-	hasIndex := a.db.Migrator().HasIndex(t, index)
+	if err := a.db.Table(tableName).AutoMigrate(t); err != nil {
+		return err
+	}
+	if err := a.createOptionalColumns(); err != nil {
+		return err
+	}
+
+	index := ruleIndexName(tableName)
+	hasIndex := a.db.Table(tableName).Migrator().HasIndex(t, index)
 	if !hasIndex {
-		if err := a.db.Exec(fmt.Sprintf("CREATE UNIQUE INDEX %s ON %s (ptype,v0,v1,v2,v3,v4,v5,v6,v7)", index, tableName)).Error; err != nil {
+		indexName, indexTable := index, tableName
+		if schema, table, ok := strings.Cut(tableName, "."); ok && isSqlite(a.db) {
+			// sqlite qualifies the index with the schema instead of the table
+			indexName, indexTable = schema+"."+index, table
+		}
+		if err := a.db.Exec("CREATE UNIQUE INDEX ? ON ? ?",
+			clause.Table{Name: indexName}, clause.Table{Name: indexTable}, a.ruleIndexColumns()).Error; err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+// createSchema creates the schema of the rule table if it does not exist.
+func (a *Adapter) createSchema() error {
+	if a.schema == "" || isSqlite(a.db) {
+		// sqlite schemas are attached databases and cannot be created
+		return nil
+	}
+	return a.db.Exec("CREATE SCHEMA IF NOT EXISTS ?", clause.Table{Name: a.schema}).Error
+}
+
 func (a *Adapter) dropTable() error {
 	t := a.db.Statement.Context.Value(customTableKey)
 	if t == nil {
@@ -411,20 +491,38 @@ func (a *Adapter) dropTable() error {
 }
 
 func (a *Adapter) truncateTable(db *gorm.DB) error {
+	return a.truncateRules(db, nil)
+}
+
+// truncateRules wipes the rule table but for the rules with the IDs in keep.
+func (a *Adapter) truncateRules(db *gorm.DB, keep []uint) error {
+	if a.tenantColumn != "" || a.sourceName != "" || a.softDelete || len(keep) > 0 {
+		// Only wipe the rows of the current tenant and source, and keep the soft-deleted ones.
+		query := db.Scopes(a.ownRules())
+		if len(keep) > 0 {
+			query = query.Where("id NOT IN ?", keep)
+		}
+		return a.write(query, OperationDelete, nil, func(db *gorm.DB) *gorm.DB {
+			return db.Delete(a.getTableInstance())
+		}).Error
+	}
+
 	var sql string
 	switch a.db.Config.Name() {
 	case sqlite.DriverName:
-		sql = fmt.Sprintf("delete from %s", a.getFullTableName())
+		sql = "delete from ?"
 	case "sqlite3":
-		sql = fmt.Sprintf("delete from %s", a.getFullTableName())
+		sql = "delete from ?"
 	case "postgres":
-		sql = fmt.Sprintf("truncate table %s RESTART IDENTITY", a.getFullTableName())
+		sql = "truncate table ? RESTART IDENTITY"
 	case "mysql":
-		sql = fmt.Sprintf("truncate table %s", a.getFullTableName())
+		sql = "truncate table ?"
 	default:
-		sql = fmt.Sprintf("truncate table %s", a.getFullTableName())
+		sql = "truncate table ?"
 	}
-	return db.Exec(sql).Error
+	return a.write(db.Table(a.getFullTableName()), OperationDelete, nil, func(db *gorm.DB) *gorm.DB {
+		return db.Exec(sql, clause.Table{Name: a.getFullTableName()})
+	}).Error
 }
 
 func loadPolicyLine(line CasbinRule, model model.Model) error {
@@ -569,10 +667,10 @@ func (a *Adapter) Transaction(e casbin.IEnforcer, fc func(casbin.IEnforcer) erro
 		}
 	}()
 
-	b := &Adapter{
-		tableName: a.tableName,
-		db:        tx,
-	}
+	// clone the adapter with all its options, only the db differs
+	clone := *a
+	clone.db = tx
+	b := &clone
 	// copy enforcer to set the new adapter with transaction tx
 	copyEnforcer := e
 	copyEnforcer.SetAdapter(b)
@@ -644,9 +742,7 @@ func (a *Adapter) rawDelete(db *gorm.DB, line CasbinRule) error {
 		queryStr += " and v7 = ?"
 		queryArgs = append(queryArgs, line.V7)
 	}
-	args := append([]interface{}{queryStr}, queryArgs...)
-	err := db.Delete(a.getTableInstance(), args...).Error
-	return err
+	return a.deleteRows(db.Where(queryStr, queryArgs...))
 }
 
 func appendWhere(line CasbinRule) (string, []interface{}) {
@@ -775,6 +871,21 @@ func (c *CasbinRule) queryString() (interface{}, []interface{}) {
 	return queryStr, queryArgs
 }
 
+// toMap returns the rule as a column-value map, as used for inserts that carry extra columns.
+func (c *CasbinRule) toMap() map[string]interface{} {
+	return map[string]interface{}{
+		"ptype": c.Ptype,
+		"v0":    c.V0,
+		"v1":    c.V1,
+		"v2":    c.V2,
+		"v3":    c.V3,
+		"v4":    c.V4,
+		"v5":    c.V5,
+		"v6":    c.V6,
+		"v7":    c.V7,
+	}
+}
+
 func (c *CasbinRule) toStringPolicy() []string {
 	policy := make([]string, 0)
 	if c.Ptype != "" {
@@ -818,15 +929,11 @@ const (
 // ConditionsToGormQuery is a function that converts multiple query conditions into a GORM query statement
 // You can use the GetAllowedObjectConditions() API of Casbin to get conditions,
 // and choose the way of combining conditions through combineType.
+// The combined conditions are wrapped in one group, so the conditions already in db still apply to every row.
+// An unknown combineType adds no condition.
 func ConditionsToGormQuery(db *gorm.DB, conditions []string, combineType CombineType) *gorm.DB {
-	queryDB := db
-	for _, cond := range conditions {
-		switch combineType {
-		case CombineTypeOr:
-			queryDB = queryDB.Or(cond)
-		case CombineTypeAnd:
-			queryDB = queryDB.Where(cond)
-		}
+	if len(conditions) == 0 || (combineType != CombineTypeOr && combineType != CombineTypeAnd) {
+		return db
 	}
-	return queryDB
+	return db.Where(CombineConditions(conditions, combineType).rawBuild())
 }
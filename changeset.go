@@ -0,0 +1,475 @@
+// Copyright 2025 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gormadapter
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	pendingTableSuffix = "_pending"
+
+	// changeSetKeysPerQuery bounds the number of rules looked up per query by a diff.
+	changeSetKeysPerQuery = 100
+	// revisionPageSize is the number of rules read per query to compute a revision.
+	revisionPageSize = 1000
+)
+
+// ErrRevisionMismatch is returned by ChangeSet.Apply when the rules changed since the expected revision.
+var ErrRevisionMismatch = errors.New("[casbin] gorm adapter error: the rules changed since the expected revision")
+
+// ErrNotApproved is returned by ChangeSet.Apply when a staged change is not approved.
+var ErrNotApproved = errors.New("[casbin] gorm adapter error: the change set is not approved")
+
+// ErrSelfApproval is returned by ChangeSet.Approve when the approver staged one of the changes.
+var ErrSelfApproval = errors.New("[casbin] gorm adapter error: changes cannot be approved by who staged them")
+
+// ChangeOp is the operation of a staged change.
+type ChangeOp string
+
+const (
+	ChangeAdd    ChangeOp = "add"
+	ChangeRemove ChangeOp = "remove"
+	ChangeUpdate ChangeOp = "update"
+)
+
+// Change is a change staged in a ChangeSet.
+type Change struct {
+	Op    ChangeOp
+	Ptype string
+	Rule  []string
+	// NewRule is the replacement of Rule for ChangeUpdate.
+	NewRule []string
+	// StagedBy is who staged the change, ApprovedBy who approved it, empty until it is approved.
+	StagedBy   string
+	ApprovedBy string
+	CreatedAt  time.Time
+}
+
+// Diff is the effect of a change set on the current rules.
+type Diff struct {
+	// Revision is the revision of the rules the diff was computed against,
+	// pass it to Apply to make sure they did not change after the review.
+	Revision string
+	Added    []CasbinRule
+	Removed  []CasbinRule
+	// Skipped are the changes without effect, e.g. adding a rule that exists.
+	Skipped []Change
+}
+
+// pendingChange is a row of the pending table.
+type pendingChange struct {
+	ID         uint   `gorm:"primaryKey;autoIncrement"`
+	ChangeSet  string `gorm:"size:100;index"`
+	Tenant     string `gorm:"size:100"`
+	Source     string `gorm:"size:100"`
+	Op         string `gorm:"size:10"`
+	Ptype      string `gorm:"size:100"`
+	Rule       string
+	NewRule    string
+	StagedBy   string `gorm:"size:100"`
+	ApprovedBy string `gorm:"size:100"`
+	CreatedAt  time.Time
+}
+
+// ChangeSet collects proposed changes to the rules in a pending table next to the rule table,
+// so that they can be reviewed with Preview before Apply writes them. The staged changes are
+// stored in the database and shared by the ChangeSets with the same name of the same tenant and source;
+// the tenant is the one of the context of each call.
+//
+// Every change records who staged it, as resolved by the actor from the context, and Apply requires
+// two persons: each change must have been approved with Approve by someone who did not stage it.
+// Pass the revision of the reviewed diff to Apply to make sure the rules did not change in between.
+//
+// Example:
+//
+//	cs, _ := NewChangeSet(a, "grant-admin-42", SubjectFromContext(userKey))
+//	_ = cs.AddPolicy(aliceCtx, "g", "g", []string{"carol", "admin"})
+//	diff, _ := cs.Preview(bobCtx)
+//	// once bob reviewed the diff
+//	_ = cs.Approve(bobCtx)
+//	_, err := cs.Apply(bobCtx, diff.Revision)
+type ChangeSet struct {
+	adapter *Adapter
+	name    string
+	actor   SubjectResolver
+}
+
+// NewChangeSet returns the change set with the given name, creating the pending table if it does not exist yet,
+// but with WithDryRun, which never changes the schema. actor resolves who stages and approves the changes
+// from the context of the calls.
+func NewChangeSet(a *Adapter, name string, actor SubjectResolver) (*ChangeSet, error) {
+	if name == "" {
+		return nil, errors.New("change set name must not be empty")
+	}
+	if actor == nil {
+		return nil, errors.New("change set needs an actor resolver")
+	}
+	if a.dryRun != nil {
+		if !primary(a.db).Migrator().HasTable(a.pendingTableName()) {
+			return nil, fmt.Errorf("the pending table %s does not exist, a dry-run adapter does not create it", a.pendingTableName())
+		}
+	} else if err := a.db.Table(a.pendingTableName()).AutoMigrate(&pendingChange{}); err != nil {
+		return nil, err
+	}
+	return &ChangeSet{adapter: a, name: name, actor: actor}, nil
+}
+
+func (a *Adapter) pendingTableName() string {
+	return a.getFullTableName() + pendingTableSuffix
+}
+
+// Name returns the name of the change set.
+func (c *ChangeSet) Name() string {
+	return c.name
+}
+
+// AddPolicy stages the addition of a policy rule.
+func (c *ChangeSet) AddPolicy(ctx context.Context, sec string, ptype string, rule []string) error {
+	return c.stage(ctx, ChangeAdd, ptype, rule, nil)
+}
+
+// RemovePolicy stages the removal of a policy rule.
+func (c *ChangeSet) RemovePolicy(ctx context.Context, sec string, ptype string, rule []string) error {
+	return c.stage(ctx, ChangeRemove, ptype, rule, nil)
+}
+
+// UpdatePolicy stages the replacement of oldRule with newRule.
+func (c *ChangeSet) UpdatePolicy(ctx context.Context, sec string, ptype string, oldRule, newRule []string) error {
+	return c.stage(ctx, ChangeUpdate, ptype, oldRule, newRule)
+}
+
+func (c *ChangeSet) stage(ctx context.Context, op ChangeOp, ptype string, rule, newRule []string) error {
+	encoded, err := json.Marshal(rule)
+	if err != nil {
+		return err
+	}
+	stagedBy, err := c.actor(ctx)
+	if err != nil {
+		return err
+	}
+	db := c.adapter.db.WithContext(ctx)
+	tenant, err := c.adapter.resolveTenant(db)
+	if err != nil {
+		return err
+	}
+	row := pendingChange{ChangeSet: c.name, Tenant: tenant, Source: c.adapter.sourceName, Op: string(op), Ptype: ptype, Rule: string(encoded),
+		StagedBy: stagedBy}
+	if newRule != nil {
+		encoded, err = json.Marshal(newRule)
+		if err != nil {
+			return err
+		}
+		row.NewRule = string(encoded)
+	}
+	staged := []CasbinRule{c.adapter.savePolicyLine(ptype, rule)}
+	return c.adapter.write(db.Table(c.adapter.pendingTableName()), OperationCreate, staged, func(db *gorm.DB) *gorm.DB {
+		return db.Create(&row)
+	}).Error
+}
+
+// pending restricts db to the staged rows of the change set in the current tenant and source.
+func (c *ChangeSet) pending(db *gorm.DB) *gorm.DB {
+	db = db.Table(c.adapter.pendingTableName()).Where("change_set = ?", c.name)
+	tenant, err := c.adapter.resolveTenant(db)
+	if err != nil {
+		_ = db.AddError(err)
+		return db
+	}
+	return db.Where("tenant = ? AND source = ?", tenant, c.adapter.sourceName)
+}
+
+// Changes returns the staged changes in the order they were staged.
+func (c *ChangeSet) Changes(ctx context.Context) ([]Change, error) {
+	return c.changes(c.adapter.db.WithContext(ctx))
+}
+
+func (c *ChangeSet) changes(db *gorm.DB) ([]Change, error) {
+	var rows []pendingChange
+	if err := c.pending(db).Order("id").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	changes := make([]Change, 0, len(rows))
+	for _, row := range rows {
+		change := Change{Op: ChangeOp(row.Op), Ptype: row.Ptype, StagedBy: row.StagedBy, ApprovedBy: row.ApprovedBy, CreatedAt: row.CreatedAt}
+		if err := json.Unmarshal([]byte(row.Rule), &change.Rule); err != nil {
+			return nil, err
+		}
+		if row.NewRule != "" {
+			if err := json.Unmarshal([]byte(row.NewRule), &change.NewRule); err != nil {
+				return nil, err
+			}
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// Preview returns the diff of the staged changes against the current rules without writing anything.
+func (c *ChangeSet) Preview(ctx context.Context) (*Diff, error) {
+	db := c.adapter.db.WithContext(ctx)
+	changes, err := c.changes(db)
+	if err != nil {
+		return nil, err
+	}
+	return c.diff(db, changes)
+}
+
+// Approve approves the staged changes that are not approved yet on behalf of the actor of ctx.
+// It fails with ErrSelfApproval if the actor staged one of them. Changes staged afterwards
+// need another approval.
+func (c *ChangeSet) Approve(ctx context.Context) error {
+	approver, err := c.actor(ctx)
+	if err != nil {
+		return err
+	}
+	db := c.adapter.db.WithContext(ctx)
+	var rows []pendingChange
+	if err := primary(c.pending(db)).Where("approved_by = ?", "").Order("id").Find(&rows).Error; err != nil {
+		return err
+	}
+	ids := make([]uint, 0, len(rows))
+	staged := make([]CasbinRule, 0, len(rows))
+	for _, row := range rows {
+		if row.StagedBy == approver {
+			return ErrSelfApproval
+		}
+		var rule []string
+		if err := json.Unmarshal([]byte(row.Rule), &rule); err != nil {
+			return err
+		}
+		ids = append(ids, row.ID)
+		staged = append(staged, c.adapter.savePolicyLine(row.Ptype, rule))
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	query := c.pending(db).Where("id IN ?", ids)
+	return c.adapter.write(query, OperationUpdate, staged, func(db *gorm.DB) *gorm.DB {
+		return db.UpdateColumn("approved_by", approver)
+	}).Error
+}
+
+// Apply writes the staged changes in one transaction and drops them. Every change must be approved,
+// else nothing is written and ErrNotApproved is returned. If expectedRevision is not empty and the rules
+// changed since that revision, nothing is written and ErrRevisionMismatch is returned.
+// It returns the applied diff; reload the enforcers to see it.
+func (c *ChangeSet) Apply(ctx context.Context, expectedRevision string) (*Diff, error) {
+	a := c.adapter
+	defer a.markWrite()
+	var diff *Diff
+	err := a.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		changes, err := c.changes(tx)
+		if err != nil {
+			return err
+		}
+		for _, change := range changes {
+			if change.ApprovedBy == "" {
+				return ErrNotApproved
+			}
+		}
+		if diff, err = c.diff(tx, changes); err != nil {
+			return err
+		}
+		if expectedRevision != "" && diff.Revision != expectedRevision {
+			return ErrRevisionMismatch
+		}
+		if len(diff.Removed) > 0 {
+			ids := make([]uint, 0, len(diff.Removed))
+			for _, line := range diff.Removed {
+				ids = append(ids, line.ID)
+			}
+			if err := a.deleteRows(tx.Scopes(a.ownRules()).Where("id IN ?", ids)); err != nil {
+				return err
+			}
+		}
+		if len(diff.Added) > 0 {
+			if err := a.createLines(tx.Scopes(a.casbinRuleTable()), diff.Added); err != nil {
+				return err
+			}
+		}
+		return c.drop(tx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return diff, nil
+}
+
+// Discard drops the staged changes.
+func (c *ChangeSet) Discard(ctx context.Context) error {
+	return c.drop(c.adapter.db.WithContext(ctx))
+}
+
+// drop deletes the staged rows. With WithDryRun the staged rules are reported as the deleted rows.
+func (c *ChangeSet) drop(db *gorm.DB) error {
+	var staged []CasbinRule
+	if c.adapter.dryRun != nil {
+		changes, err := c.changes(primary(db))
+		if err != nil {
+			return err
+		}
+		staged = make([]CasbinRule, 0, len(changes))
+		for _, change := range changes {
+			staged = append(staged, c.adapter.savePolicyLine(change.Ptype, change.Rule))
+		}
+	}
+	return c.adapter.write(c.pending(db), OperationDelete, staged, func(db *gorm.DB) *gorm.DB {
+		return db.Delete(&pendingChange{})
+	}).Error
+}
+
+// diff replays the staged changes on the current rules.
+func (c *ChangeSet) diff(db *gorm.DB, changes []Change) (*Diff, error) {
+	a := c.adapter
+	revision, err := a.revision(db)
+	if err != nil {
+		return nil, err
+	}
+	staged := make([]CasbinRule, 0, len(changes))
+	for _, change := range changes {
+		staged = append(staged, a.savePolicyLine(change.Ptype, change.Rule))
+		if change.Op == ChangeUpdate {
+			staged = append(staged, a.savePolicyLine(change.Ptype, change.NewRule))
+		}
+	}
+	lines, err := a.rulesLike(db, staged)
+	if err != nil {
+		return nil, err
+	}
+
+	initial := make(map[string]CasbinRule, len(lines))
+	present := make(map[string]bool, len(lines))
+	for _, line := range lines {
+		initial[ruleKey(line)] = line
+		present[ruleKey(line)] = true
+	}
+	var touched []CasbinRule
+	seen := make(map[string]bool)
+	set := func(line CasbinRule, want bool) {
+		key := ruleKey(line)
+		present[key] = want
+		if !seen[key] {
+			seen[key] = true
+			touched = append(touched, line)
+		}
+	}
+
+	diff := &Diff{Revision: revision}
+	for _, change := range changes {
+		line := a.savePolicyLine(change.Ptype, change.Rule)
+		switch change.Op {
+		case ChangeAdd:
+			if present[ruleKey(line)] {
+				diff.Skipped = append(diff.Skipped, change)
+				continue
+			}
+			set(line, true)
+		case ChangeRemove:
+			if !present[ruleKey(line)] {
+				diff.Skipped = append(diff.Skipped, change)
+				continue
+			}
+			set(line, false)
+		case ChangeUpdate:
+			newLine := a.savePolicyLine(change.Ptype, change.NewRule)
+			if !present[ruleKey(line)] || present[ruleKey(newLine)] {
+				diff.Skipped = append(diff.Skipped, change)
+				continue
+			}
+			set(line, false)
+			set(newLine, true)
+		}
+	}
+
+	for _, line := range touched {
+		key := ruleKey(line)
+		old, existed := initial[key]
+		switch {
+		case present[key] && !existed:
+			diff.Added = append(diff.Added, line)
+		case !present[key] && existed:
+			diff.Removed = append(diff.Removed, old)
+		}
+	}
+	return diff, nil
+}
+
+// rulesLike returns the stored rules of the adapter's source valid now that are equal to one of lines.
+func (a *Adapter) rulesLike(db *gorm.DB, lines []CasbinRule) ([]CasbinRule, error) {
+	var rules []CasbinRule
+	for start := 0; start < len(lines); start += changeSetKeysPerQuery {
+		end := min(start+changeSetKeysPerQuery, len(lines))
+		var sql strings.Builder
+		vars := make([]interface{}, 0, (end-start)*9)
+		for i, line := range lines[start:end] {
+			if i > 0 {
+				sql.WriteString(" OR ")
+			}
+			sql.WriteString("(ptype = ? AND v0 = ? AND v1 = ? AND v2 = ? AND v3 = ? AND v4 = ? AND v5 = ? AND v6 = ? AND v7 = ?)")
+			vars = append(vars, line.Ptype, line.V0, line.V1, line.V2, line.V3, line.V4, line.V5, line.V6, line.V7)
+		}
+		var chunk []CasbinRule
+		if err := db.Scopes(a.ownRules(), a.validNow()).
+			Where(clause.Expr{SQL: "(" + sql.String() + ")", Vars: vars}).Order("id").Find(&chunk).Error; err != nil {
+			return nil, err
+		}
+		rules = append(rules, chunk...)
+	}
+	return rules, nil
+}
+
+// Revision returns a fingerprint of the current rules, which changes whenever a rule is added, removed or updated.
+func (a *Adapter) Revision(ctx context.Context) (string, error) {
+	return a.revision(a.db.WithContext(ctx).Scopes(a.readScope()))
+}
+
+// revision reads the rules in pages and sums the hashes of the rules, so that the fingerprint
+// does not depend on the order of the rows.
+func (a *Adapter) revision(db *gorm.DB) (string, error) {
+	var sum [4]uint64
+	var count uint64
+	var page []CasbinRule
+	err := db.Scopes(a.casbinRuleTable(), a.validNow()).FindInBatches(&page, revisionPageSize, func(tx *gorm.DB, batch int) error {
+		for _, line := range page {
+			h := sha256.Sum256([]byte(ruleKey(line)))
+			for i := range sum {
+				sum[i] += binary.BigEndian.Uint64(h[i*8:])
+			}
+			count++
+		}
+		return nil
+	}).Error
+	if err != nil {
+		return "", err
+	}
+	var b [40]byte
+	for i, v := range sum {
+		binary.BigEndian.PutUint64(b[i*8:], v)
+	}
+	binary.BigEndian.PutUint64(b[32:], count)
+	return hex.EncodeToString(b[:]), nil
+}
@@ -0,0 +1,231 @@
+// Copyright 2025 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gormadapter
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/anzimu/casbin/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChangeSet(t *testing.T) {
+	db := openSqliteTestDB(t)
+	a, err := NewAdapterByDBWithOptions(db, "", "", WithAutoMigrate())
+	assert.NoError(t, err)
+	ctx := asUser(context.Background(), "alice")
+	assert.NoError(t, a.AddPolicies("p", "p", [][]string{{"alice", "data1", "read"}, {"bob", "data2", "write"}}))
+
+	cs, err := NewChangeSet(a, "grant-42", changeActor)
+	assert.NoError(t, err)
+	assert.NoError(t, cs.AddPolicy(ctx, "g", "g", []string{"carol", "data2_admin"}))
+	assert.NoError(t, cs.AddPolicy(ctx, "p", "p", []string{"alice", "data1", "read"}))
+	assert.NoError(t, cs.RemovePolicy(ctx, "p", "p", []string{"bob", "data2", "write"}))
+	assert.NoError(t, cs.UpdatePolicy(ctx, "p", "p", []string{"alice", "data1", "read"}, []string{"alice", "data1", "write"}))
+
+	changes, err := cs.Changes(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, changes, 4)
+
+	// Staging does not touch the live rules.
+	e, err := casbin.NewEnforcer("examples/rbac_model.conf", a)
+	assert.NoError(t, err)
+	testGetPolicy(t, e, [][]string{{"alice", "data1", "read"}, {"bob", "data2", "write"}})
+
+	diff, err := cs.Preview(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"carol", "data2_admin"}, {"alice", "data1", "write"}}, policiesOf(diff.Added))
+	assert.Equal(t, [][]string{{"bob", "data2", "write"}, {"alice", "data1", "read"}}, policiesOf(diff.Removed))
+	assert.Len(t, diff.Skipped, 1)
+	revision, err := a.Revision(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, revision, diff.Revision)
+
+	reviewer := asUser(context.Background(), "bob")
+	assert.NoError(t, cs.Approve(reviewer))
+	applied, err := cs.Apply(reviewer, diff.Revision)
+	assert.NoError(t, err)
+	assert.Equal(t, policiesOf(diff.Added), policiesOf(applied.Added))
+	assert.NoError(t, e.LoadPolicy())
+	testGetPolicy(t, e, [][]string{{"alice", "data1", "write"}})
+	assert.Equal(t, [][]string{{"carol", "data2_admin"}}, e.GetGroupingPolicy())
+
+	changes, err = cs.Changes(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+}
+
+func TestChangeSetRevisionMismatch(t *testing.T) {
+	db := openSqliteTestDB(t)
+	a, err := NewAdapterByDBWithOptions(db, "", "", WithAutoMigrate())
+	assert.NoError(t, err)
+	ctx := asUser(context.Background(), "alice")
+
+	cs, err := NewChangeSet(a, "grant-43", changeActor)
+	assert.NoError(t, err)
+	assert.NoError(t, cs.AddPolicy(ctx, "p", "p", []string{"alice", "data1", "read"}))
+	diff, err := cs.Preview(ctx)
+	assert.NoError(t, err)
+	assert.NoError(t, cs.Approve(asUser(ctx, "bob")))
+
+	assert.NoError(t, a.AddPolicy("p", "p", []string{"bob", "data2", "write"}))
+	_, err = cs.Apply(ctx, diff.Revision)
+	assert.ErrorIs(t, err, ErrRevisionMismatch)
+	changes, err := cs.Changes(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, changes, 1)
+
+	assert.NoError(t, cs.Discard(ctx))
+	changes, err = cs.Changes(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+	count, err := a.CountRules(ctx, Query{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
+
+// changeActor resolves who stages and approves the changes in the tests.
+var changeActor = SubjectFromContext(userKey{})
+
+// asUser returns ctx acting as user.
+func asUser(ctx context.Context, user string) context.Context {
+	return context.WithValue(ctx, userKey{}, user)
+}
+
+func policiesOf(lines []CasbinRule) [][]string {
+	policies := make([][]string, 0, len(lines))
+	for _, line := range lines {
+		policies = append(policies, line.toStringPolicy()[1:])
+	}
+	return policies
+}
+
+func TestChangeSetTenants(t *testing.T) {
+	db := openSqliteTestDB(t)
+	a, err := NewAdapterByDBWithOptions(db, "", "", WithTenant("tenant", TenantFromContext(tenantCtxKey{})), WithAutoMigrate())
+	assert.NoError(t, err)
+	ctx1 := asUser(context.WithValue(context.Background(), tenantCtxKey{}, "t1"), "alice")
+	ctx2 := asUser(context.WithValue(context.Background(), tenantCtxKey{}, "t2"), "alice")
+
+	cs, err := NewChangeSet(a, "grant-44", changeActor)
+	assert.NoError(t, err)
+	assert.NoError(t, cs.AddPolicy(ctx1, "p", "p", []string{"alice", "data1", "read"}))
+
+	// Another tenant neither sees nor applies nor discards the change set of t1.
+	changes, err := cs.Changes(ctx2)
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+	diff, err := cs.Apply(ctx2, "")
+	assert.NoError(t, err)
+	assert.Empty(t, diff.Added)
+	assert.NoError(t, cs.Discard(ctx2))
+
+	assert.NoError(t, cs.Approve(asUser(ctx1, "bob")))
+	diff, err = cs.Apply(ctx1, "")
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"alice", "data1", "read"}}, policiesOf(diff.Added))
+	count, err := a.CountRules(ctx2, Query{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestRevision(t *testing.T) {
+	db := openSqliteTestDB(t)
+	a, err := NewAdapterByDBWithOptions(db, "", "", WithAutoMigrate())
+	assert.NoError(t, err)
+	ctx := context.Background()
+	empty, err := a.Revision(ctx)
+	assert.NoError(t, err)
+
+	rules := make([][]string, 0, revisionPageSize+1)
+	for i := 0; i <= revisionPageSize; i++ {
+		rules = append(rules, []string{fmt.Sprintf("user%d", i), "data1", "read"})
+	}
+	assert.NoError(t, a.AddPolicies("p", "p", rules))
+	full, err := a.Revision(ctx)
+	assert.NoError(t, err)
+	assert.NotEqual(t, empty, full)
+
+	// The revision depends on the rules only, not on the order of the rows.
+	assert.NoError(t, a.RemovePolicy("p", "p", rules[0]))
+	assert.NoError(t, a.AddPolicy("p", "p", rules[0]))
+	again, err := a.Revision(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, full, again)
+	assert.NoError(t, a.RemovePolicy("p", "p", rules[0]))
+	removed, err := a.Revision(ctx)
+	assert.NoError(t, err)
+	assert.NotEqual(t, full, removed)
+}
+
+func TestChangeSetSources(t *testing.T) {
+	db := openSqliteTestDB(t)
+	iac, err := NewAdapterByDBWithOptions(db, "", "", WithSource("iac"), WithAutoMigrate())
+	assert.NoError(t, err)
+	ui, err := NewAdapterByDBWithOptions(db, "", "", WithSource("ui"))
+	assert.NoError(t, err)
+	ctx := asUser(context.Background(), "alice")
+	assert.NoError(t, iac.AddPolicy("p", "p", []string{"alice", "data1", "read"}))
+
+	// The change set of the ui does not remove the rules of the iac.
+	cs, err := NewChangeSet(ui, "grant-46", changeActor)
+	assert.NoError(t, err)
+	assert.NoError(t, cs.RemovePolicy(ctx, "p", "p", []string{"alice", "data1", "read"}))
+	assert.NoError(t, cs.Approve(asUser(ctx, "bob")))
+	diff, err := cs.Apply(ctx, "")
+	assert.NoError(t, err)
+	assert.Empty(t, diff.Removed)
+	assert.Len(t, diff.Skipped, 1)
+	assert.Equal(t, map[string]string{"alice": "iac"}, sources(t, db))
+}
+
+func TestChangeSetApproval(t *testing.T) {
+	db := openSqliteTestDB(t)
+	a, err := NewAdapterByDBWithOptions(db, "", "", WithAutoMigrate())
+	assert.NoError(t, err)
+	alice, bob := asUser(context.Background(), "alice"), asUser(context.Background(), "bob")
+
+	_, err = NewChangeSet(a, "grant-47", nil)
+	assert.Error(t, err)
+	cs, err := NewChangeSet(a, "grant-47", changeActor)
+	assert.NoError(t, err)
+	assert.ErrorIs(t, cs.AddPolicy(context.Background(), "g", "g", []string{"carol", "admin"}), ErrSubjectNotFound)
+	assert.NoError(t, cs.AddPolicy(alice, "g", "g", []string{"carol", "admin"}))
+
+	// Nobody applies an unapproved change, and the stager cannot approve it.
+	_, err = cs.Apply(alice, "")
+	assert.ErrorIs(t, err, ErrNotApproved)
+	assert.ErrorIs(t, cs.Approve(alice), ErrSelfApproval)
+	assert.NoError(t, cs.Approve(bob))
+
+	// A change staged after the approval needs its own.
+	assert.NoError(t, cs.AddPolicy(bob, "g", "g", []string{"dave", "admin"}))
+	_, err = cs.Apply(bob, "")
+	assert.ErrorIs(t, err, ErrNotApproved)
+	assert.ErrorIs(t, cs.Approve(bob), ErrSelfApproval)
+	assert.NoError(t, cs.Approve(alice))
+
+	changes, err := cs.Changes(alice)
+	assert.NoError(t, err)
+	if assert.Len(t, changes, 2) {
+		assert.Equal(t, []string{"alice", "bob"}, []string{changes[0].StagedBy, changes[0].ApprovedBy})
+		assert.Equal(t, []string{"bob", "alice"}, []string{changes[1].StagedBy, changes[1].ApprovedBy})
+	}
+	diff, err := cs.Apply(alice, "")
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"carol", "admin"}, {"dave", "admin"}}, policiesOf(diff.Added))
+}
@@ -0,0 +1,469 @@
+// Copyright 2025 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gormadapter
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+const defaultConditionPrefix = "r.obj."
+
+// ErrUntranslatableCondition is returned for a condition that cannot be translated into a safe query.
+var ErrUntranslatableCondition = errors.New("[casbin] gorm adapter error: untranslatable condition")
+
+// ConditionOptions configures the safe translation of conditions.
+//
+// A condition compares fields with literals, e.g. `price > 20` or `category_id == 1 && r.obj.author == 'x'`,
+// with the operators ==, =, !=, <>, <, <=, >, >=, &&, ||, ! and parentheses. Literals are numbers,
+// quoted strings, true and false. Only the whitelisted fields may be referred to.
+type ConditionOptions struct {
+	// Fields maps the allowed fields to their columns.
+	Fields map[string]string
+	// Model is a gorm model whose fields are allowed, by field name or column name.
+	Model interface{}
+	// Prefix is stripped from the fields, "r.obj." by default.
+	Prefix string
+}
+
+// SafeConditionsToGormQuery is the safe mode of ConditionsToGormQuery: every condition is parsed, its fields
+// are mapped to whitelisted columns and its values are passed as parameters. A condition that cannot be
+// translated is rejected with ErrUntranslatableCondition.
+//
+// Example:
+//
+//	conditions, _ := e.GetAllowedObjectConditions("alice", "read", "r.obj.")
+//	db, err := SafeConditionsToGormQuery(db, conditions, CombineTypeOr, ConditionOptions{Model: &Book{}})
+//	err = db.Find(&books).Error
+func SafeConditionsToGormQuery(db *gorm.DB, conditions []string, combineType CombineType, opts ConditionOptions) (*gorm.DB, error) {
+	if combineType != CombineTypeOr && combineType != CombineTypeAnd {
+		return nil, fmt.Errorf("unknown combine type %d", combineType)
+	}
+	if len(conditions) == 0 {
+		return db, nil
+	}
+	expr, err := CombineConditions(conditions, combineType).safeBuild(db, opts)
+	if err != nil {
+		return nil, err
+	}
+	return db.Where(expr), nil
+}
+
+// ConditionTree composes conditions with AND, OR and NOT. It is always built into one parenthesized
+// group, so that it is safe to combine with the other conditions of a query.
+//
+// Example:
+//
+//	// (A AND B) OR NOT C
+//	tree := AnyConds(AllConds(Cond(a), Cond(b)), NotCond(Cond(c)))
+//	err := db.Scopes(tenantScope, tree.Scope()).Find(&books).Error
+type ConditionTree struct {
+	op    string
+	cond  string
+	nodes []ConditionTree
+}
+
+// Cond is a single condition.
+func Cond(cond string) ConditionTree {
+	return ConditionTree{cond: cond}
+}
+
+// AllConds matches rows that match all of nodes.
+func AllConds(nodes ...ConditionTree) ConditionTree {
+	return ConditionTree{op: "AND", nodes: nodes}
+}
+
+// AnyConds matches rows that match any of nodes.
+func AnyConds(nodes ...ConditionTree) ConditionTree {
+	return ConditionTree{op: "OR", nodes: nodes}
+}
+
+// NotCond matches rows that do not match node.
+func NotCond(node ConditionTree) ConditionTree {
+	return ConditionTree{op: "NOT", nodes: []ConditionTree{node}}
+}
+
+// CombineConditions combines conditions with OR for CombineTypeOr and with AND for CombineTypeAnd.
+// For an unknown combineType the tree has no condition and matches every row, like ConditionsToGormQuery.
+func CombineConditions(conditions []string, combineType CombineType) ConditionTree {
+	nodes := make([]ConditionTree, 0, len(conditions))
+	for _, cond := range conditions {
+		nodes = append(nodes, Cond(cond))
+	}
+	switch combineType {
+	case CombineTypeOr:
+		return AnyConds(nodes...)
+	case CombineTypeAnd:
+		return AllConds(nodes...)
+	default:
+		return AllConds()
+	}
+}
+
+// Scope returns a gorm scope restricting a query to the rows matching the tree.
+// The conditions are raw SQL, see SafeScope for untrusted conditions.
+func (t ConditionTree) Scope() func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(t.rawBuild())
+	}
+}
+
+func (t ConditionTree) rawBuild() clause.Expression {
+	expr, _ := t.build(func(cond string) (clause.Expression, error) {
+		return clause.Expr{SQL: cond}, nil
+	})
+	return expr
+}
+
+// SafeScope returns a gorm scope restricting a query to the rows matching the tree, with the conditions
+// translated as by SafeConditionsToGormQuery. A condition that cannot be translated fails the query.
+func (t ConditionTree) SafeScope(opts ConditionOptions) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		expr, err := t.safeBuild(db, opts)
+		if err != nil {
+			_ = db.AddError(err)
+			return db
+		}
+		return db.Where(expr)
+	}
+}
+
+func (t ConditionTree) safeBuild(db *gorm.DB, opts ConditionOptions) (clause.Expression, error) {
+	resolve, err := opts.resolver(db)
+	if err != nil {
+		return nil, err
+	}
+	return t.parse(resolve)
+}
+
+// parse builds the tree with the conditions parsed and their fields mapped by resolve.
+func (t ConditionTree) parse(resolve func(field string) (string, error)) (clause.Expression, error) {
+	return t.build(func(cond string) (clause.Expression, error) {
+		return parseCondition(cond, resolve)
+	})
+}
+
+// build builds the tree into one group, with leaf building the conditions.
+func (t ConditionTree) build(leaf func(cond string) (clause.Expression, error)) (clause.Expression, error) {
+	if t.op == "" {
+		expr, err := leaf(t.cond)
+		if err != nil {
+			return nil, err
+		}
+		return clause.Expr{SQL: "(?)", Vars: []interface{}{expr}}, nil
+	}
+	if len(t.nodes) == 0 {
+		if t.op == "OR" {
+			return clause.Expr{SQL: "(1 = 0)"}, nil
+		}
+		return clause.Expr{SQL: "(1 = 1)"}, nil
+	}
+
+	parts := make([]string, 0, len(t.nodes))
+	vars := make([]interface{}, 0, len(t.nodes))
+	for _, node := range t.nodes {
+		expr, err := node.build(leaf)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, "?")
+		vars = append(vars, expr)
+	}
+	if t.op == "NOT" {
+		return clause.Expr{SQL: "(NOT ?)", Vars: vars}, nil
+	}
+	return clause.Expr{SQL: "(" + strings.Join(parts, " "+t.op+" ") + ")", Vars: vars}, nil
+}
+
+// resolver returns the function mapping the fields of a condition to columns.
+func (opts ConditionOptions) resolver(db *gorm.DB) (func(field string) (string, error), error) {
+	var s *schema.Schema
+	if opts.Model != nil {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(opts.Model); err != nil {
+			return nil, err
+		}
+		s = stmt.Schema
+	}
+	return opts.schemaResolver(s)
+}
+
+// schemaResolver returns the function mapping the fields of a condition to the columns of Fields and s.
+func (opts ConditionOptions) schemaResolver(s *schema.Schema) (func(field string) (string, error), error) {
+	prefix := opts.Prefix
+	if prefix == "" {
+		prefix = defaultConditionPrefix
+	}
+	if opts.Fields == nil && s == nil {
+		return nil, errors.New("no fields are allowed in conditions, set ConditionOptions.Fields or Model")
+	}
+	return func(field string) (string, error) {
+		field = strings.TrimPrefix(field, prefix)
+		if column, ok := opts.Fields[field]; ok {
+			return column, nil
+		}
+		if s != nil {
+			if f := s.LookUpField(field); f != nil && f.DBName != "" {
+				return f.DBName, nil
+			}
+		}
+		return "", fmt.Errorf("%w: field %q is not allowed", ErrUntranslatableCondition, field)
+	}, nil
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenNumber
+	tokenString
+	tokenOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+var conditionOps = []string{"==", "!=", "<>", "<=", ">=", "&&", "||", "=", "<", ">", "!", "(", ")"}
+
+func tokenize(cond string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(cond); {
+		c := rune(cond[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '\'' || c == '"':
+			var b strings.Builder
+			j := i + 1
+			for ; j < len(cond) && rune(cond[j]) != c; j++ {
+				if cond[j] == '\\' && j+1 < len(cond) {
+					j++
+				}
+				b.WriteByte(cond[j])
+			}
+			if j == len(cond) {
+				return nil, fmt.Errorf("%w: unterminated string in %q", ErrUntranslatableCondition, cond)
+			}
+			tokens = append(tokens, token{tokenString, b.String()})
+			i = j + 1
+		case unicode.IsDigit(c) || (c == '-' && i+1 < len(cond) && unicode.IsDigit(rune(cond[i+1]))):
+			j := i + 1
+			for j < len(cond) && (unicode.IsDigit(rune(cond[j])) || cond[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, token{tokenNumber, cond[i:j]})
+			i = j
+		case unicode.IsLetter(c) || c == '_':
+			j := i + 1
+			for j < len(cond) && (unicode.IsLetter(rune(cond[j])) || unicode.IsDigit(rune(cond[j])) || cond[j] == '_' || cond[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, token{tokenIdent, cond[i:j]})
+			i = j
+		default:
+			op := ""
+			for _, o := range conditionOps {
+				if strings.HasPrefix(cond[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("%w: unexpected %q in %q", ErrUntranslatableCondition, cond[i:i+1], cond)
+			}
+			tokens = append(tokens, token{tokenOp, op})
+			i += len(op)
+		}
+	}
+	return append(tokens, token{kind: tokenEOF}), nil
+}
+
+// conditionParser parses a condition into a parameterized clause:
+//
+//	or         = and { "||" and }
+//	and        = unary { "&&" unary }
+//	unary      = "!" unary | "(" or ")" | comparison
+//	comparison = field op literal
+type conditionParser struct {
+	cond    string
+	tokens  []token
+	pos     int
+	resolve func(field string) (string, error)
+}
+
+func parseCondition(cond string, resolve func(field string) (string, error)) (clause.Expression, error) {
+	tokens, err := tokenize(cond)
+	if err != nil {
+		return nil, err
+	}
+	p := &conditionParser{cond: cond, tokens: tokens, resolve: resolve}
+	expr, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokenEOF {
+		return nil, p.unexpected()
+	}
+	return expr, nil
+}
+
+func (p *conditionParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *conditionParser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *conditionParser) accept(op string) bool {
+	if t := p.peek(); t.kind == tokenOp && t.text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *conditionParser) unexpected() error {
+	t := p.peek()
+	if t.kind == tokenEOF {
+		return fmt.Errorf("%w: unexpected end of %q", ErrUntranslatableCondition, p.cond)
+	}
+	return fmt.Errorf("%w: unexpected %q in %q", ErrUntranslatableCondition, t.text, p.cond)
+}
+
+func (p *conditionParser) or() (clause.Expression, error) {
+	return p.binary("||", p.and, clause.Or)
+}
+
+func (p *conditionParser) and() (clause.Expression, error) {
+	return p.binary("&&", p.unary, clause.And)
+}
+
+func (p *conditionParser) binary(op string, operand func() (clause.Expression, error), combine func(...clause.Expression) clause.Expression) (clause.Expression, error) {
+	expr, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	exprs := []clause.Expression{expr}
+	for p.accept(op) {
+		if expr, err = operand(); err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+	}
+	if len(exprs) == 1 {
+		return exprs[0], nil
+	}
+	return combine(exprs...), nil
+}
+
+func (p *conditionParser) unary() (clause.Expression, error) {
+	if p.accept("!") {
+		expr, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		// clause.Not negates each operand of an AND, so the negation is built explicitly.
+		return clause.Expr{SQL: "NOT (?)", Vars: []interface{}{expr}}, nil
+	}
+	if p.accept("(") {
+		expr, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, p.unexpected()
+		}
+		return clause.And(expr), nil
+	}
+	return p.comparison()
+}
+
+func (p *conditionParser) comparison() (clause.Expression, error) {
+	if p.peek().kind != tokenIdent {
+		return nil, p.unexpected()
+	}
+	name, err := p.resolve(p.next().text)
+	if err != nil {
+		return nil, err
+	}
+	column := clause.Column{Name: name}
+
+	op := p.peek()
+	if op.kind != tokenOp {
+		return nil, p.unexpected()
+	}
+	p.next()
+	value, err := p.literal()
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.text {
+	case "==", "=":
+		return clause.Eq{Column: column, Value: value}, nil
+	case "!=", "<>":
+		return clause.Neq{Column: column, Value: value}, nil
+	case "<":
+		return clause.Lt{Column: column, Value: value}, nil
+	case "<=":
+		return clause.Lte{Column: column, Value: value}, nil
+	case ">":
+		return clause.Gt{Column: column, Value: value}, nil
+	case ">=":
+		return clause.Gte{Column: column, Value: value}, nil
+	}
+	return nil, fmt.Errorf("%w: unexpected %q in %q", ErrUntranslatableCondition, op.text, p.cond)
+}
+
+func (p *conditionParser) literal() (interface{}, error) {
+	t := p.peek()
+	switch t.kind {
+	case tokenString:
+		p.next()
+		return t.text, nil
+	case tokenNumber:
+		p.next()
+		if n, err := strconv.ParseInt(t.text, 10, 64); err == nil {
+			return n, nil
+		}
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid number %q in %q", ErrUntranslatableCondition, t.text, p.cond)
+		}
+		return f, nil
+	case tokenIdent:
+		switch t.text {
+		case "true", "false":
+			p.next()
+			return t.text == "true", nil
+		}
+	}
+	return nil, p.unexpected()
+}
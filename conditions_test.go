@@ -0,0 +1,132 @@
+// Copyright 2025 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gormadapter
+
+import (
+	"testing"
+
+	"github.com/anzimu/casbin/v2"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type Book struct {
+	ID         int
+	Title      string
+	Author     string
+	Publisher  string
+	Price      float64
+	CategoryID int
+}
+
+// initBooks creates the books of the ConditionsToGormQuery example in the README.
+func initBooks(t *testing.T) *gorm.DB {
+	db := openSqliteTestDB(t)
+	assert.NoError(t, db.AutoMigrate(&Book{}))
+	assert.NoError(t, db.Create([]Book{
+		{1, "book1", "author1", "publisher1", 10, 1},
+		{2, "book2", "author1", "publisher1", 20, 2},
+		{3, "book3", "author2", "publisher1", 30, 1},
+		{4, "book4", "author2", "publisher2", 10, 3},
+		{5, "book5", "author3", "publisher2", 50, 1},
+		{6, "book6", "author3", "publisher2", 60, 2},
+	}).Error)
+	return db
+}
+
+func bookIDs(t *testing.T, db *gorm.DB) []int {
+	var ids []int
+	assert.NoError(t, db.Model(&Book{}).Order("id").Pluck("id", &ids).Error)
+	return ids
+}
+
+func TestSafeConditionsToGormQuery(t *testing.T) {
+	db := initBooks(t)
+	e, err := casbin.NewEnforcer("examples/object_conditions_model.conf", "examples/object_conditions_policy.csv")
+	assert.NoError(t, err)
+	conditions, err := e.GetAllowedObjectConditions("alice", "read", "r.obj.")
+	assert.NoError(t, err)
+
+	opts := ConditionOptions{Model: &Book{}}
+	q, err := SafeConditionsToGormQuery(db, conditions, CombineTypeOr, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 4, 6}, bookIDs(t, q))
+	q, err = SafeConditionsToGormQuery(db, conditions, CombineTypeAnd, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2}, bookIDs(t, q))
+
+	q, err = SafeConditionsToGormQuery(db, []string{
+		"category_id == 1 && r.obj.author == 'author2'",
+		"!(Price <= 50 || author != \"author3\")",
+	}, CombineTypeOr, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{3, 6}, bookIDs(t, q))
+
+	q, err = SafeConditionsToGormQuery(db, []string{"cost >= 30"}, CombineTypeOr,
+		ConditionOptions{Fields: map[string]string{"cost": "price"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{3, 5, 6}, bookIDs(t, q))
+}
+
+func TestSafeConditionsToGormQueryRejects(t *testing.T) {
+	db := initBooks(t)
+	opts := ConditionOptions{Fields: map[string]string{"price": "price", "title": "title"}}
+
+	for _, cond := range []string{
+		"price < 25 OR 1=1",
+		"title == 'x'; DROP TABLE books",
+		"author == 'author1'",
+		"price < (SELECT 1)",
+		"title == author",
+		"title == 'unterminated",
+		"(price < 25",
+		"price <",
+	} {
+		_, err := SafeConditionsToGormQuery(db, []string{cond}, CombineTypeOr, opts)
+		assert.ErrorIs(t, err, ErrUntranslatableCondition, cond)
+	}
+
+	_, err := SafeConditionsToGormQuery(db, []string{"price < 25"}, CombineTypeOr, ConditionOptions{})
+	assert.Error(t, err)
+}
+
+func TestConditionsToGormQueryGrouping(t *testing.T) {
+	db := initBooks(t)
+	conditions := []string{"price < 25", "category_id = 2"}
+
+	// The conditions must not widen the existing condition.
+	q := ConditionsToGormQuery(db.Where("publisher = ?", "publisher1"), conditions, CombineTypeOr)
+	assert.Equal(t, []int{1, 2}, bookIDs(t, q))
+	q = ConditionsToGormQuery(db.Where("publisher = ?", "publisher2"), conditions[:1], CombineTypeOr)
+	assert.Equal(t, []int{4}, bookIDs(t, q))
+	q = ConditionsToGormQuery(db.Where("publisher = ?", "publisher2"), conditions, CombineTypeAnd)
+	assert.Empty(t, bookIDs(t, q))
+	// An unknown combine type adds no condition, as it always did.
+	q = ConditionsToGormQuery(db.Where("publisher = ?", "publisher2"), conditions, CombineType(7))
+	assert.Equal(t, []int{4, 5, 6}, bookIDs(t, q))
+
+	// (author = 'author1' AND price > 10) OR NOT category_id = 1
+	tree := AnyConds(AllConds(Cond("author = 'author1'"), Cond("price > 10")), NotCond(Cond("category_id = 1")))
+	assert.Equal(t, []int{2, 4, 6}, bookIDs(t, db.Scopes(tree.Scope())))
+	publisher2 := func(db *gorm.DB) *gorm.DB { return db.Where("publisher = ?", "publisher2") }
+	assert.Equal(t, []int{4, 6}, bookIDs(t, db.Scopes(tree.Scope(), publisher2)))
+	assert.Empty(t, bookIDs(t, db.Scopes(AnyConds().Scope())))
+	assert.Len(t, bookIDs(t, db.Scopes(AllConds().Scope())), 6)
+
+	safe := AnyConds(AllConds(Cond("author == 'author1'"), Cond("price > 10")), NotCond(Cond("category_id == 1")))
+	assert.Equal(t, []int{4, 6}, bookIDs(t, db.Scopes(safe.SafeScope(ConditionOptions{Model: &Book{}}), publisher2)))
+	err := db.Scopes(NotCond(Cond("1 = 1")).SafeScope(ConditionOptions{Model: &Book{}})).Find(&[]Book{}).Error
+	assert.ErrorIs(t, err, ErrUntranslatableCondition)
+}
@@ -47,6 +47,15 @@ func NewContextAdapterByDBWithCustomTable(gormCtxKey interface{}, db *gorm.DB, t
 	}, err
 }
 
+// NewContextAdapterByDBWithOptions creates a ContextAdapter by an existing Gorm instance and applies the given options.
+func NewContextAdapterByDBWithOptions(gormCtxKey interface{}, db *gorm.DB, prefix string, tableName string, opts ...Option) (*ContextAdapter, error) {
+	a, err := NewAdapterByDBWithOptions(db, prefix, tableName, opts...)
+	return &ContextAdapter{
+		a,
+		gormCtxKey,
+	}, err
+}
+
 // executeWithContext is a helper function to execute a function with context and return the result or error.
 func executeWithContext(ctx context.Context, fn func() error) error {
 	done := make(chan error)
@@ -80,7 +89,11 @@ func executeWithContextEx(ctx context.Context, fn func() ([][]string, error)) ([
 
 func (ca *ContextAdapter) getDBByCtx(ctx context.Context) (*gorm.DB, bool) {
 	db, ok := ctx.Value(ca.gormCtxKey).(*gorm.DB)
-	return db, ok
+	if !ok {
+		return nil, false
+	}
+	// Run with ctx so that context based options, e.g. TenantFromContext, can see it.
+	return db.WithContext(ctx), true
 }
 
 // TransactionCtx perform a set of operations within a transaction
@@ -250,3 +263,48 @@ func (ca *ContextAdapter) UpdateFilteredPoliciesCtx(ctx context.Context, sec str
 		return ca.updateFilteredPolicies(db, sec, ptype, newRules, fieldIndex, fieldValues...)
 	})
 }
+
+// RemoveFilteredPolicyByExprCtx removes the policy rules that match the filter expression from the storage with context.
+func (ca *ContextAdapter) RemoveFilteredPolicyByExprCtx(ctx context.Context, expr FilterExpr) error {
+	return executeWithContext(ctx, func() error {
+		db, ok := ca.getDBByCtx(ctx)
+		if !ok {
+			return CtxWithoutDBError
+		}
+		return ca.removeFilteredPolicyByExpr(db, expr)
+	})
+}
+
+// SubjectsForCtx returns the subjects that may perform act on obj with context, see SubjectsFor.
+func (ca *ContextAdapter) SubjectsForCtx(ctx context.Context, m model.Model, ptype, obj, act string, domain ...string) ([]string, error) {
+	var subjects []string
+	if err := executeWithContext(ctx, func() error {
+		db, ok := ca.getDBByCtx(ctx)
+		if !ok {
+			return CtxWithoutDBError
+		}
+		var err error
+		subjects, err = ca.subjectsFor(db, m, ptype, obj, act, domain...)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return subjects, nil
+}
+
+// ObjectsForCtx returns the objects on which sub may perform act with context, see ObjectsFor.
+func (ca *ContextAdapter) ObjectsForCtx(ctx context.Context, m model.Model, ptype, sub, act string, domain ...string) ([]string, error) {
+	var objects []string
+	if err := executeWithContext(ctx, func() error {
+		db, ok := ca.getDBByCtx(ctx)
+		if !ok {
+			return CtxWithoutDBError
+		}
+		var err error
+		objects, err = ca.objectsFor(db, m, ptype, sub, act, domain...)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return objects, nil
+}
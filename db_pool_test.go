@@ -0,0 +1,81 @@
+// Copyright 2025 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gormadapter
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/anzimu/casbin/v2"
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestInitDbResolverErrors(t *testing.T) {
+	_, err := InitDbResolver(nil, nil)
+	assert.Error(t, err)
+
+	dir := t.TempDir()
+	d1 := sqlite.Open(filepath.Join(dir, "casbin1.db"))
+	d2 := sqlite.Open(filepath.Join(dir, "casbin2.db"))
+	_, err = InitDbResolver([]gorm.Dialector{d1, d2}, []string{"casbin1"})
+	assert.Error(t, err)
+	_, err = InitDbResolver([]gorm.Dialector{d1, d2}, []string{"casbin1", "casbin1"})
+	assert.Error(t, err)
+
+	dbPool, err := InitDbResolver([]gorm.Dialector{d1}, []string{"casbin1"})
+	assert.NoError(t, err)
+	_, err = NewAdapterByMulDb(dbPool, "casbin3", "", "casbin_rule")
+	assert.Error(t, err)
+	assert.NoError(t, dbPool.Close())
+}
+
+func TestDbPoolConcurrentAdapters(t *testing.T) {
+	dir := t.TempDir()
+	dbPool, err := InitDbResolver([]gorm.Dialector{
+		sqlite.Open(filepath.Join(dir, "casbin1.db")),
+		sqlite.Open(filepath.Join(dir, "casbin2.db")),
+	}, []string{"casbin1", "casbin2"})
+	assert.NoError(t, err)
+	defer dbPool.Close()
+
+	a1, err := NewAdapterByMulDb(dbPool, "casbin1", "", "casbin_rule", true)
+	assert.NoError(t, err)
+	a2, err := NewAdapterByMulDb(dbPool, "casbin2", "", "casbin_rule", true)
+	assert.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i, a := range []*Adapter{a1, a2} {
+		wg.Add(1)
+		go func(i int, a *Adapter) {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				assert.NoError(t, a.AddPolicy("p", "p", []string{fmt.Sprintf("user%d", i), fmt.Sprintf("data%d", j), "read"}))
+				e, _ := casbin.NewEnforcer("examples/rbac_model.conf")
+				assert.NoError(t, a.LoadPolicy(e.GetModel()))
+			}
+		}(i, a)
+	}
+	wg.Wait()
+
+	for i, a := range []*Adapter{a1, a2} {
+		e, _ := casbin.NewEnforcer("examples/rbac_model.conf", a)
+		assert.Len(t, e.GetPolicy(), 20)
+		assert.Len(t, e.GetFilteredPolicy(0, fmt.Sprintf("user%d", i)), 20)
+	}
+}
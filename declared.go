@@ -0,0 +1,176 @@
+// Copyright 2025 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gormadapter
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// declaredTag is the struct tag declaring the policies of a model.
+	declaredTag = "casbin"
+	// declaredColumn marks the rules added by SyncDeclaredPolicies.
+	declaredColumn = "declared"
+)
+
+// WithPruneDeclaredPolicies makes SyncDeclaredPolicies remove the rules it added earlier
+// that are no longer declared. With WithAutoMigrate, the declared marker column is added to the rule table.
+func WithPruneDeclaredPolicies() Option {
+	return func(a *Adapter) error {
+		a.pruneDeclared = true
+		return nil
+	}
+}
+
+// parseDeclaration parses a tag like `role=editor,act=read|write` into its policy rules.
+// The keys are role and act, and optionally obj (the table of the model by default),
+// dom for a domain and ptype ("p" by default).
+func (a *Adapter) parseDeclaration(tag string, table string) ([]CasbinRule, error) {
+	values := map[string]string{"ptype": "p", "obj": table}
+	for _, pair := range strings.Split(tag, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		switch {
+		case !ok || value == "":
+			return nil, fmt.Errorf("invalid casbin tag %q", tag)
+		case key != "ptype" && key != "role" && key != "dom" && key != "obj" && key != "act":
+			return nil, fmt.Errorf("unknown key %q in casbin tag %q", key, tag)
+		}
+		values[key] = value
+	}
+	if values["role"] == "" || values["act"] == "" {
+		return nil, fmt.Errorf("casbin tag %q needs a role and an act", tag)
+	}
+
+	var rules []CasbinRule
+	for _, act := range strings.Split(values["act"], "|") {
+		rule := []string{values["role"], values["obj"], act}
+		if dom, ok := values["dom"]; ok {
+			rule = []string{values["role"], dom, values["obj"], act}
+		}
+		rules = append(rules, a.savePolicyLine(values["ptype"], rule))
+	}
+	return rules, nil
+}
+
+// declaredRules returns the rules declared by the casbin tags of the fields of the models.
+func (a *Adapter) declaredRules(models []interface{}) ([]CasbinRule, error) {
+	var rules []CasbinRule
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: a.db}
+		if err := stmt.Parse(model); err != nil {
+			return nil, err
+		}
+		t := modelType(model)
+		for i := 0; i < t.NumField(); i++ {
+			tag, ok := t.Field(i).Tag.Lookup(declaredTag)
+			if !ok {
+				continue
+			}
+			declared, err := a.parseDeclaration(tag, stmt.Schema.Table)
+			if err != nil {
+				return nil, fmt.Errorf("%v: %w", t, err)
+			}
+			rules = append(rules, declared...)
+		}
+	}
+	return rules, nil
+}
+
+func ruleKey(line CasbinRule) string {
+	return strings.Join([]string{line.Ptype, line.V0, line.V1, line.V2, line.V3, line.V4, line.V5, line.V6, line.V7}, "\x00")
+}
+
+// createDeclaredColumn adds the declared marker column to the rule table if it does not exist yet.
+func (a *Adapter) createDeclaredColumn() error {
+	if !a.pruneDeclared {
+		return nil
+	}
+	return a.ensureColumn(declaredColumn, "SMALLINT NOT NULL DEFAULT 0")
+}
+
+// SyncDeclaredPolicies reconciles the policies declared by the casbin struct tags of the models
+// into the rule table. A tag may be on any field, usually a blank one:
+//
+//	type Article struct {
+//		_  struct{} `casbin:"role=editor,act=read|write"`
+//		ID uint
+//	}
+//
+// declares the rules "p, editor, articles, read" and "p, editor, articles, write". The missing rules are
+// added with a declared marker, if the rule table has the marker column, see WithPruneDeclaredPolicies.
+// With WithPruneDeclaredPolicies, the marked rules that are no longer declared are removed.
+// Rules added otherwise, e.g. by an admin UI, are never changed nor removed.
+func (a *Adapter) SyncDeclaredPolicies(models ...interface{}) error {
+	rules, err := a.declaredRules(models)
+	if err != nil {
+		return err
+	}
+	marked := primary(a.db).Migrator().HasColumn(a.getFullTableName(), declaredColumn)
+	if a.pruneDeclared && !marked {
+		return fmt.Errorf("the rule table has no %s column, migrate it with WithAutoMigrate and WithPruneDeclaredPolicies", declaredColumn)
+	}
+	var extra map[string]interface{}
+	if marked {
+		extra = map[string]interface{}{declaredColumn: 1}
+	}
+
+	defer a.markWrite()
+	return a.db.Transaction(func(tx *gorm.DB) error {
+		declared := make(map[string]bool)
+		var missing []CasbinRule
+		for _, rule := range rules {
+			key := ruleKey(rule)
+			if declared[key] {
+				continue
+			}
+			declared[key] = true
+			var count int64
+			if err := tx.Scopes(a.casbinRuleTable()).Where(rule.toMap()).Count(&count).Error; err != nil {
+				return err
+			}
+			if count == 0 {
+				missing = append(missing, rule)
+			}
+		}
+		if len(missing) > 0 {
+			if err := a.createLinesWith(tx.Scopes(a.casbinRuleTable()), missing, extra); err != nil {
+				return err
+			}
+		}
+		if !a.pruneDeclared {
+			return nil
+		}
+
+		var marked []CasbinRule
+		if err := tx.Scopes(a.casbinRuleTable()).Where(clause.Eq{Column: clause.Column{Name: declaredColumn}, Value: 1}).
+			Find(&marked).Error; err != nil {
+			return err
+		}
+		var stale []uint
+		for _, line := range marked {
+			if !declared[ruleKey(line)] {
+				stale = append(stale, line.ID)
+			}
+		}
+		if len(stale) == 0 {
+			return nil
+		}
+		return a.deleteRows(tx.Scopes(a.casbinRuleTable()).Where("id IN ?", stale))
+	})
+}
@@ -0,0 +1,118 @@
+// Copyright 2025 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gormadapter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/anzimu/casbin/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+type Article struct {
+	_     struct{} `casbin:"role=editor,act=read|write"`
+	ID    uint
+	Title string `casbin:"role=reader,act=read"`
+}
+
+type Invoice struct {
+	_  struct{} `casbin:"role=accountant,dom=finance,obj=billing,act=approve"`
+	ID uint
+}
+
+type ArticleV2 struct {
+	_ struct{} `casbin:"role=editor,act=read"`
+}
+
+func (ArticleV2) TableName() string { return "articles" }
+
+// storedRules returns the rules of the table in insertion order, ptype first.
+func storedRules(t *testing.T, a *Adapter) [][]string {
+	lines, _, err := a.ListRules(context.Background(), Query{})
+	assert.NoError(t, err)
+	var rules [][]string
+	for _, line := range lines {
+		rules = append(rules, line.toStringPolicy())
+	}
+	return rules
+}
+
+func TestSyncDeclaredPolicies(t *testing.T) {
+	db := openSqliteTestDB(t)
+	a, err := NewAdapterByDBWithOptions(db, "", "", WithAutoMigrate(), WithPruneDeclaredPolicies())
+	assert.NoError(t, err)
+	// A rule from the admin UI that is also declared, and one that is not.
+	assert.NoError(t, a.AddPolicies("p", "p", [][]string{{"reader", "articles", "read"}, {"editor", "articles", "write"}}))
+
+	declared := [][]string{
+		{"p", "reader", "articles", "read"}, {"p", "editor", "articles", "write"},
+		{"p", "editor", "articles", "read"}, {"p", "accountant", "finance", "billing", "approve"},
+	}
+	assert.NoError(t, a.SyncDeclaredPolicies(&Article{}, Invoice{}))
+	assert.Equal(t, declared, storedRules(t, a))
+
+	// Syncing again changes nothing.
+	assert.NoError(t, a.SyncDeclaredPolicies(&Article{}, Invoice{}))
+	assert.Equal(t, declared, storedRules(t, a))
+
+	// Only the declared rules that are no longer declared are removed, editor write comes from the admin UI.
+	assert.NoError(t, a.SyncDeclaredPolicies(&ArticleV2{}))
+	assert.Equal(t, declared[:3], storedRules(t, a))
+
+	// Without pruning stale rules are kept.
+	b, err := NewAdapterByDBWithOptions(db, "", "")
+	assert.NoError(t, err)
+	assert.NoError(t, b.SyncDeclaredPolicies(Invoice{}))
+	assert.NoError(t, b.SyncDeclaredPolicies(&ArticleV2{}))
+	assert.Equal(t, declared, storedRules(t, b))
+
+	type invalid struct {
+		_ struct{} `casbin:"role=editor"`
+	}
+	assert.Error(t, a.SyncDeclaredPolicies(&invalid{}))
+}
+
+func TestSyncDeclaredPoliciesSavePolicy(t *testing.T) {
+	db := openSqliteTestDB(t)
+	a, err := NewAdapterByDBWithOptions(db, "", "", WithAutoMigrate(), WithPruneDeclaredPolicies())
+	assert.NoError(t, err)
+	assert.NoError(t, a.SyncDeclaredPolicies(&Article{}))
+
+	// SavePolicy writes the rules back with their declared marker, new rules are not declared.
+	e, err := casbin.NewEnforcer("examples/rbac_model.conf", a)
+	assert.NoError(t, err)
+	_, err = e.AddPolicy("admin", "articles", "write")
+	assert.NoError(t, err)
+	assert.NoError(t, e.SavePolicy())
+
+	assert.NoError(t, a.SyncDeclaredPolicies(&ArticleV2{}))
+	assert.Equal(t, [][]string{{"p", "editor", "articles", "read"}, {"p", "admin", "articles", "write"}}, storedRules(t, a))
+}
+
+func TestSyncDeclaredPoliciesTransaction(t *testing.T) {
+	db := openSqliteTestDB(t)
+	a, err := NewAdapterByDBWithOptions(db, "", "", WithAutoMigrate(), WithPruneDeclaredPolicies())
+	assert.NoError(t, err)
+	assert.NoError(t, a.SyncDeclaredPolicies(&Article{}))
+	e, err := casbin.NewEnforcer("examples/rbac_model.conf", a)
+	assert.NoError(t, err)
+
+	// The adapter of the transaction keeps the options of the adapter.
+	assert.NoError(t, a.Transaction(e, func(e casbin.IEnforcer) error {
+		return e.GetAdapter().(*Adapter).SyncDeclaredPolicies(&ArticleV2{})
+	}))
+	assert.Equal(t, [][]string{{"p", "editor", "articles", "read"}}, storedRules(t, a))
+}
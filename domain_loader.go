@@ -0,0 +1,252 @@
+// Copyright 2025 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gormadapter
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/anzimu/casbin/v2"
+	"github.com/anzimu/casbin/v2/model"
+)
+
+const defaultDomainField = "dom"
+
+// DomainLoaderConfig configures a DomainLoader.
+type DomainLoaderConfig struct {
+	// Capacity is the maximum number of domains kept in memory. It must be positive.
+	Capacity int
+	// TTL is how long a loaded domain is used before it is reloaded from the database. Zero means forever.
+	TTL time.Duration
+	// DomainField is the name of the domain field in the request and policy definitions, "dom" by default.
+	DomainField string
+}
+
+// roleLinkBuilder is implemented by the enforcers that can update their role links incrementally.
+type roleLinkBuilder interface {
+	BuildIncrementalRoleLinks(op model.PolicyOp, ptype string, rules [][]string) error
+}
+
+type loadedDomain struct {
+	domain   string
+	loadedAt time.Time
+}
+
+// DomainLoader loads the p and g rules of a domain into the enforcer on the first enforcement in that domain,
+// and unloads the least recently used domains beyond its capacity.
+// Use the Enforce method of the DomainLoader instead of the one of the enforcer;
+// calls are serialized, so the enforcer should not be used concurrently by others.
+type DomainLoader struct {
+	enforcer    casbin.IEnforcer
+	roleLinks   roleLinkBuilder
+	adapter     *Adapter
+	capacity    int
+	ttl         time.Duration
+	domainField string
+	// requestIndex is the position of the domain in the enforce request.
+	requestIndex int
+
+	mu      sync.Mutex
+	lru     *list.List
+	domains map[string]*list.Element
+	now     func() time.Time
+}
+
+// NewDomainLoader creates a DomainLoader on an enforcer whose model has domains, e.g. rbac_with_domains.
+// The current policy of the enforcer is cleared.
+func NewDomainLoader(e casbin.IEnforcer, a *Adapter, config DomainLoaderConfig) (*DomainLoader, error) {
+	if config.Capacity <= 0 {
+		return nil, errors.New("domain loader capacity must be positive")
+	}
+	if config.DomainField == "" {
+		config.DomainField = defaultDomainField
+	}
+	roleLinks, ok := e.(roleLinkBuilder)
+	if !ok {
+		return nil, errors.New("enforcer cannot build role links incrementally")
+	}
+
+	requestIndex := -1
+	if ast, ok := e.GetModel()["r"]["r"]; ok {
+		for i, token := range ast.Tokens {
+			if token == "r_"+config.DomainField {
+				requestIndex = i
+			}
+		}
+	}
+	if requestIndex == -1 {
+		return nil, fmt.Errorf("request definition has no %q field", config.DomainField)
+	}
+
+	e.ClearPolicy()
+	return &DomainLoader{
+		enforcer:     e,
+		roleLinks:    roleLinks,
+		adapter:      a,
+		capacity:     config.Capacity,
+		ttl:          config.TTL,
+		domainField:  config.DomainField,
+		requestIndex: requestIndex,
+		lru:          list.New(),
+		domains:      make(map[string]*list.Element),
+		now:          time.Now,
+	}, nil
+}
+
+// Enforce loads the domain of the request if needed and enforces it.
+func (l *DomainLoader) Enforce(rvals ...interface{}) (bool, error) {
+	if l.requestIndex >= len(rvals) {
+		return false, fmt.Errorf("request has no %q field", l.domainField)
+	}
+	domain, ok := rvals[l.requestIndex].(string)
+	if !ok {
+		return false, fmt.Errorf("%q of the request is not a string", l.domainField)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := l.ensure(domain); err != nil {
+		return false, err
+	}
+	return l.enforcer.Enforce(rvals...)
+}
+
+// LoadDomain loads the rules of domain unless they are loaded already.
+func (l *DomainLoader) LoadDomain(domain string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.ensure(domain)
+}
+
+// UnloadDomain removes the rules of domain from the enforcer.
+func (l *DomainLoader) UnloadDomain(domain string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if elem, ok := l.domains[domain]; ok {
+		return l.unload(elem)
+	}
+	return nil
+}
+
+// LoadedDomains returns the domains in memory, sorted by name.
+func (l *DomainLoader) LoadedDomains() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	domains := make([]string, 0, len(l.domains))
+	for domain := range l.domains {
+		domains = append(domains, domain)
+	}
+	sort.Strings(domains)
+	return domains
+}
+
+func (l *DomainLoader) ensure(domain string) error {
+	if elem, ok := l.domains[domain]; ok {
+		if l.ttl == 0 || l.now().Sub(elem.Value.(*loadedDomain).loadedAt) < l.ttl {
+			l.lru.MoveToFront(elem)
+			return nil
+		}
+		// expired, reload it from the database
+		if err := l.unload(elem); err != nil {
+			return err
+		}
+	}
+
+	for l.lru.Len() >= l.capacity {
+		if err := l.unload(l.lru.Back()); err != nil {
+			return err
+		}
+	}
+
+	filters, err := l.domainFilters(domain)
+	if err != nil {
+		return err
+	}
+	m := l.enforcer.GetModel()
+	if err := l.adapter.LoadFilteredPolicy(m, filters); err != nil {
+		return err
+	}
+	for ptype := range m["g"] {
+		if index, ok := l.domainIndex("g", ptype); ok {
+			rules := m.GetFilteredPolicy("g", ptype, index, domain)
+			if err := l.roleLinks.BuildIncrementalRoleLinks(model.PolicyAdd, ptype, rules); err != nil {
+				return err
+			}
+		}
+	}
+	l.domains[domain] = l.lru.PushFront(&loadedDomain{domain: domain, loadedAt: l.now()})
+	return nil
+}
+
+func (l *DomainLoader) unload(elem *list.Element) error {
+	domain := elem.Value.(*loadedDomain).domain
+	m := l.enforcer.GetModel()
+	for _, sec := range []string{"p", "g"} {
+		for ptype := range m[sec] {
+			index, ok := l.domainIndex(sec, ptype)
+			if !ok {
+				continue
+			}
+			_, removed := m.RemoveFilteredPolicy(sec, ptype, index, domain)
+			if sec == "g" {
+				if err := l.roleLinks.BuildIncrementalRoleLinks(model.PolicyRemove, ptype, removed); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	l.lru.Remove(elem)
+	delete(l.domains, domain)
+	return nil
+}
+
+// domainIndex returns the column index of the domain in the rules of ptype, if they have one.
+func (l *DomainLoader) domainIndex(sec string, ptype string) (int, bool) {
+	fields, err := fieldIndexes(l.enforcer.GetModel(), ptype)
+	if err != nil {
+		return 0, false
+	}
+	index, ok := fields[l.fieldOf(sec)]
+	return index, ok
+}
+
+// fieldOf returns the name of the domain field in the rules of sec.
+func (l *DomainLoader) fieldOf(sec string) string {
+	if sec == "g" {
+		return "domain"
+	}
+	return l.domainField
+}
+
+// domainFilters returns the filters of all rules of domain.
+func (l *DomainLoader) domainFilters(domain string) ([]Filter, error) {
+	b := NewFilterBuilder(l.enforcer.GetModel())
+	for _, sec := range []string{"p", "g"} {
+		for ptype := range l.enforcer.GetModel()[sec] {
+			if _, ok := l.domainIndex(sec, ptype); ok {
+				b.ForPtype(ptype).Where(l.fieldOf(sec), domain)
+			}
+		}
+	}
+	return b.Build()
+}
@@ -0,0 +1,112 @@
+// Copyright 2025 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gormadapter
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/anzimu/casbin/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// initDomainPolicy scales examples/rbac_with_domains_policy.csv up to n domains:
+// admin may read and write dataN in domainN, and userN is admin of domainN.
+func initDomainPolicy(t *testing.T, n int) *Adapter {
+	db := openSqliteTestDB(t)
+	a, err := NewAdapterByDBWithOptions(db, "", "", WithAutoMigrate())
+	assert.NoError(t, err)
+
+	var p, g [][]string
+	for i := 0; i < n; i++ {
+		domain, data := fmt.Sprintf("domain%d", i), fmt.Sprintf("data%d", i)
+		p = append(p, []string{"admin", domain, data, "read"}, []string{"admin", domain, data, "write"})
+		g = append(g, []string{fmt.Sprintf("user%d", i), "admin", domain})
+	}
+	assert.NoError(t, a.AddPolicies("p", "p", p))
+	assert.NoError(t, a.AddPolicies("g", "g", g))
+	return a
+}
+
+func TestDomainLoader(t *testing.T) {
+	a := initDomainPolicy(t, 50)
+	e, err := casbin.NewEnforcer("examples/rbac_with_domains_model.conf")
+	assert.NoError(t, err)
+	l, err := NewDomainLoader(e, a, DomainLoaderConfig{Capacity: 3})
+	assert.NoError(t, err)
+
+	for i := 0; i < 5; i++ {
+		ok, err := l.Enforce(fmt.Sprintf("user%d", i), fmt.Sprintf("domain%d", i), fmt.Sprintf("data%d", i), "read")
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	}
+	assert.Equal(t, []string{"domain2", "domain3", "domain4"}, l.LoadedDomains())
+	assert.Len(t, e.GetPolicy(), 6)
+	assert.Len(t, e.GetGroupingPolicy(), 3)
+
+	// A user has no access to another domain, loading it evicts the least recently used domain2.
+	ok, err := l.Enforce("user3", "domain7", "data7", "read")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, []string{"domain3", "domain4", "domain7"}, l.LoadedDomains())
+	assert.Empty(t, e.GetFilteredPolicy(1, "domain2"))
+	assert.Empty(t, e.GetFilteredGroupingPolicy(2, "domain2"))
+
+	// Evicted domains are loaded again on demand.
+	ok, err = l.Enforce("user2", "domain2", "data2", "write")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"domain2", "domain4", "domain7"}, l.LoadedDomains())
+
+	assert.NoError(t, l.UnloadDomain("domain7"))
+	assert.Equal(t, []string{"domain2", "domain4"}, l.LoadedDomains())
+	assert.Len(t, e.GetPolicy(), 4)
+
+	_, err = l.Enforce("user2")
+	assert.Error(t, err)
+}
+
+func TestDomainLoaderTTL(t *testing.T) {
+	a := initDomainPolicy(t, 2)
+	e, _ := casbin.NewEnforcer("examples/rbac_with_domains_model.conf")
+	l, err := NewDomainLoader(e, a, DomainLoaderConfig{Capacity: 10, TTL: time.Minute})
+	assert.NoError(t, err)
+	now := time.Now()
+	l.now = func() time.Time { return now }
+
+	ok, _ := l.Enforce("user0", "domain0", "data0", "read")
+	assert.True(t, ok)
+	assert.NoError(t, a.RemovePolicy("g", "g", []string{"user0", "admin", "domain0"}))
+
+	// Still cached within the TTL.
+	ok, _ = l.Enforce("user0", "domain0", "data0", "read")
+	assert.True(t, ok)
+
+	now = now.Add(2 * time.Minute)
+	ok, _ = l.Enforce("user0", "domain0", "data0", "read")
+	assert.False(t, ok)
+	assert.Len(t, e.GetPolicy(), 2)
+}
+
+func TestDomainLoaderConfig(t *testing.T) {
+	a := initDomainPolicy(t, 1)
+	e, _ := casbin.NewEnforcer("examples/rbac_with_domains_model.conf")
+	_, err := NewDomainLoader(e, a, DomainLoaderConfig{})
+	assert.Error(t, err)
+	e, _ = casbin.NewEnforcer("examples/rbac_model.conf")
+	_, err = NewDomainLoader(e, a, DomainLoaderConfig{Capacity: 1})
+	assert.Error(t, err)
+}
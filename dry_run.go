@@ -0,0 +1,98 @@
+// Copyright 2025 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gormadapter
+
+import (
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// DryRunEntry is a write statement recorded by an adapter built with WithDryRun.
+type DryRunEntry struct {
+	// Operation is OperationCreate, OperationUpdate or OperationDelete.
+	// Soft deletes are recorded as OperationDelete.
+	Operation string
+	// SQL is the statement with its variables inlined, for reading only.
+	SQL string
+	// Rows are the rows the statement would insert, or the matched rows as they are for updates and deletes.
+	// For the pending table of a ChangeSet, they are the staged rules.
+	Rows []CasbinRule
+}
+
+// dryRunRecorder collects the entries of an adapter in dry-run mode.
+type dryRunRecorder struct {
+	mu      sync.Mutex
+	entries []DryRunEntry
+}
+
+// WithDryRun makes the adapter compute the writes of its mutating methods without executing them:
+// every statement is generated through a gorm DryRun session and recorded, with the rows it would affect,
+// in the report returned by DryRunReport. The reads still run against the database, but the schema is never
+// changed: WithAutoMigrate is ignored and the rule table, and the pending table of a ChangeSet, must exist.
+func WithDryRun() Option {
+	return func(a *Adapter) error {
+		a.dryRun = &dryRunRecorder{}
+		return nil
+	}
+}
+
+// DryRunReport returns the write statements recorded since the adapter was created or the report was reset,
+// in the order they were generated. It is empty without WithDryRun.
+func (a *Adapter) DryRunReport() []DryRunEntry {
+	if a.dryRun == nil {
+		return nil
+	}
+	a.dryRun.mu.Lock()
+	defer a.dryRun.mu.Unlock()
+	return append([]DryRunEntry(nil), a.dryRun.entries...)
+}
+
+// ResetDryRunReport clears the recorded write statements.
+func (a *Adapter) ResetDryRunReport() {
+	if a.dryRun == nil {
+		return
+	}
+	a.dryRun.mu.Lock()
+	defer a.dryRun.mu.Unlock()
+	a.dryRun.entries = nil
+}
+
+// write runs exec on query. With WithDryRun it only generates the statement and records it together with
+// the rows it would affect: lines if given, else for updates and deletes the rows matched by query.
+// RowsAffected is then the number of these rows.
+func (a *Adapter) write(query *gorm.DB, operation string, lines []CasbinRule, exec func(db *gorm.DB) *gorm.DB) *gorm.DB {
+	if a.dryRun == nil {
+		return exec(query)
+	}
+	if lines == nil && operation != OperationCreate {
+		if result := primary(query.Session(&gorm.Session{})).Find(&lines); result.Error != nil {
+			return result
+		}
+	}
+	tx := exec(query.Session(&gorm.Session{DryRun: true}))
+	if tx.Error != nil {
+		return tx
+	}
+	a.dryRun.mu.Lock()
+	a.dryRun.entries = append(a.dryRun.entries, DryRunEntry{
+		Operation: operation,
+		SQL:       tx.Dialector.Explain(tx.Statement.SQL.String(), tx.Statement.Vars...),
+		Rows:      lines,
+	})
+	a.dryRun.mu.Unlock()
+	tx.RowsAffected = int64(len(lines))
+	return tx
+}
@@ -0,0 +1,170 @@
+// Copyright 2025 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gormadapter
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anzimu/casbin/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDryRun(t *testing.T) {
+	db := openSqliteTestDB(t)
+	live, err := NewAdapterByDBWithOptions(db, "", "", WithAutoMigrate())
+	assert.NoError(t, err)
+	assert.NoError(t, live.AddPolicies("p", "p", [][]string{{"alice", "data1", "read"}, {"bob", "data2", "write"}}))
+
+	a, err := NewAdapterByDBWithOptions(db, "", "", WithDryRun())
+	assert.NoError(t, err)
+	assert.NoError(t, a.AddPolicies("p", "p", [][]string{{"carol", "data3", "read"}}))
+	assert.NoError(t, a.RemoveFilteredPolicy("p", "p", 1, "data2"))
+	assert.NoError(t, a.UpdatePolicy("p", "p", []string{"alice", "data1", "read"}, []string{"alice", "data1", "write"}))
+	_, err = a.UpdateFilteredPolicies("p", "p", [][]string{{"alice", "data9", "read"}}, 0, "alice")
+	assert.NoError(t, err)
+
+	report := a.DryRunReport()
+	assert.Len(t, report, 5)
+	assert.Equal(t, OperationCreate, report[0].Operation)
+	assert.True(t, strings.HasPrefix(report[0].SQL, "INSERT INTO"))
+	assert.Contains(t, report[0].SQL, "carol")
+	assert.Equal(t, [][]string{{"carol", "data3", "read"}}, policiesOf(report[0].Rows))
+	assert.Equal(t, OperationDelete, report[1].Operation)
+	assert.True(t, strings.HasPrefix(report[1].SQL, "DELETE FROM"))
+	assert.Equal(t, [][]string{{"bob", "data2", "write"}}, policiesOf(report[1].Rows))
+	assert.Equal(t, OperationUpdate, report[2].Operation)
+	assert.Contains(t, report[2].SQL, "UPDATE")
+	assert.Equal(t, [][]string{{"alice", "data1", "read"}}, policiesOf(report[2].Rows))
+	assert.Equal(t, OperationDelete, report[3].Operation)
+	assert.Equal(t, OperationCreate, report[4].Operation)
+
+	// Nothing was written.
+	e, err := casbin.NewEnforcer("examples/rbac_model.conf", live)
+	assert.NoError(t, err)
+	testGetPolicy(t, e, [][]string{{"alice", "data1", "read"}, {"bob", "data2", "write"}})
+
+	a.ResetDryRunReport()
+	assert.Empty(t, a.DryRunReport())
+}
+
+func TestDryRunSavePolicy(t *testing.T) {
+	db := openSqliteTestDB(t)
+	live, err := NewAdapterByDBWithOptions(db, "", "", WithAutoMigrate())
+	assert.NoError(t, err)
+	assert.NoError(t, live.AddPolicies("p", "p", [][]string{{"alice", "data1", "read"}, {"bob", "data2", "write"}}))
+
+	a, err := NewAdapterByDBWithOptions(db, "", "", WithDryRun())
+	assert.NoError(t, err)
+	e, err := casbin.NewEnforcer("examples/rbac_model.conf", a)
+	assert.NoError(t, err)
+	_, err = e.RemovePolicy("bob", "data2", "write")
+	assert.NoError(t, err)
+	a.ResetDryRunReport()
+	assert.NoError(t, e.SavePolicy())
+
+	report := a.DryRunReport()
+	assert.Len(t, report, 2)
+	assert.Equal(t, OperationDelete, report[0].Operation)
+	assert.Len(t, report[0].Rows, 2)
+	assert.Equal(t, OperationCreate, report[1].Operation)
+	assert.Equal(t, [][]string{{"alice", "data1", "read"}}, policiesOf(report[1].Rows))
+
+	count, err := live.CountRules(context.Background(), Query{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+}
+
+func TestDryRunPurgeExpired(t *testing.T) {
+	db := openSqliteTestDB(t)
+	live, err := NewAdapterByDBWithOptions(db, "", "", WithValidity(), WithAutoMigrate())
+	assert.NoError(t, err)
+	past := time.Now().Add(-time.Minute)
+	for _, user := range []string{"u1", "u2", "u3"} {
+		assert.NoError(t, live.AddPolicyUntil("p", "p", []string{user, "data1", "read"}, past))
+	}
+
+	a, err := NewAdapterByDBWithOptions(db, "", "", WithValidity(), WithDryRun())
+	assert.NoError(t, err)
+	purged, err := a.PurgeExpired(context.Background(), 2)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), purged)
+	assert.Len(t, a.DryRunReport(), 1)
+	var count int64
+	assert.NoError(t, db.Table("casbin_rule").Count(&count).Error)
+	assert.Equal(t, int64(3), count)
+}
+
+func TestDryRunChangeSet(t *testing.T) {
+	db := openSqliteTestDB(t)
+	live, err := NewAdapterByDBWithOptions(db, "", "", WithAutoMigrate())
+	assert.NoError(t, err)
+	ctx := asUser(context.Background(), "alice")
+	staged, err := NewChangeSet(live, "grant-45", changeActor)
+	assert.NoError(t, err)
+	assert.NoError(t, staged.AddPolicy(ctx, "p", "p", []string{"alice", "data1", "read"}))
+
+	a, err := NewAdapterByDBWithOptions(db, "", "", WithDryRun())
+	assert.NoError(t, err)
+	cs, err := NewChangeSet(a, "grant-45", changeActor)
+	assert.NoError(t, err)
+	assert.NoError(t, cs.AddPolicy(ctx, "p", "p", []string{"bob", "data2", "write"}))
+	assert.NoError(t, cs.Discard(ctx))
+
+	report := a.DryRunReport()
+	assert.Len(t, report, 2)
+	assert.Equal(t, OperationCreate, report[0].Operation)
+	assert.Equal(t, [][]string{{"bob", "data2", "write"}}, policiesOf(report[0].Rows))
+	assert.Equal(t, OperationDelete, report[1].Operation)
+	assert.Equal(t, [][]string{{"alice", "data1", "read"}}, policiesOf(report[1].Rows))
+
+	changes, err := staged.Changes(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, changes, 1)
+}
+
+func TestDryRunSchema(t *testing.T) {
+	db := openSqliteTestDB(t)
+
+	// Neither the rule table nor the pending table nor the declared column is created.
+	a, err := NewAdapterByDBWithOptions(db, "", "", WithDryRun(), WithPruneDeclaredPolicies(), WithAutoMigrate())
+	assert.NoError(t, err)
+	assert.False(t, db.Migrator().HasTable("casbin_rule"))
+	_, err = NewChangeSet(a, "grant-48", changeActor)
+	assert.Error(t, err)
+	assert.False(t, db.Migrator().HasTable("casbin_rule_pending"))
+
+	_, err = NewAdapterByDBWithOptions(db, "", "", WithAutoMigrate())
+	assert.NoError(t, err)
+	a, err = NewAdapterByDBWithOptions(db, "", "", WithDryRun())
+	assert.NoError(t, err)
+	assert.NoError(t, a.SyncDeclaredPolicies(&Article{}))
+	assert.False(t, db.Migrator().HasColumn("casbin_rule", declaredColumn))
+	report := a.DryRunReport()
+	if assert.Len(t, report, 1) {
+		assert.Equal(t, OperationCreate, report[0].Operation)
+		assert.Len(t, report[0].Rows, 3)
+	}
+	var count int64
+	assert.NoError(t, db.Table("casbin_rule").Count(&count).Error)
+	assert.Equal(t, int64(0), count)
+
+	// Pruning needs the declared column of the migration.
+	prune, err := NewAdapterByDBWithOptions(db, "", "", WithPruneDeclaredPolicies())
+	assert.NoError(t, err)
+	assert.Error(t, prune.SyncDeclaredPolicies(&Article{}))
+}
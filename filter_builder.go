@@ -0,0 +1,153 @@
+// Copyright 2025 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gormadapter
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/anzimu/casbin/v2/model"
+)
+
+// roleFieldNames are the field names of role definitions, which have no names of their own in the model.
+var roleFieldNames = map[string]int{"user": 0, "role": 1, "domain": 2, "dom": 2}
+
+// FilterBuilder builds filters from the field names of the casbin model
+// instead of the V0-V7 column positions.
+//
+// Example:
+//
+//	b := NewFilterBuilder(e.GetModel())
+//	b.ForPtype("p").Where("dom", "domain1")
+//	b.ForPtype("g").Where("domain", "domain1")
+//	filters, err := b.Build()
+type FilterBuilder struct {
+	model  model.Model
+	ptypes []*PtypeFilter
+}
+
+// PtypeFilter is the filter of one policy or role type of a FilterBuilder.
+type PtypeFilter struct {
+	model  model.Model
+	ptype  string
+	values [8][]string
+	err    error
+}
+
+// NewFilterBuilder creates a FilterBuilder for the model.
+func NewFilterBuilder(m model.Model) *FilterBuilder {
+	return &FilterBuilder{model: m}
+}
+
+// ForPtype starts the filter of the rules of ptype, e.g. "p" or "g2".
+func (b *FilterBuilder) ForPtype(ptype string) *PtypeFilter {
+	f := &PtypeFilter{model: b.model, ptype: ptype}
+	if b.model == nil {
+		f.err = errors.New("filter builder has no model")
+	} else if _, err := fieldIndexes(b.model, ptype); err != nil {
+		f.err = err
+	}
+	b.ptypes = append(b.ptypes, f)
+	return f
+}
+
+// Build returns one filter per ptype, to be passed to LoadFilteredPolicy.
+func (b *FilterBuilder) Build() ([]Filter, error) {
+	filters := make([]Filter, 0, len(b.ptypes))
+	for _, p := range b.ptypes {
+		f, err := p.Build()
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, f)
+	}
+	return filters, nil
+}
+
+// Where restricts the named field to values.
+func (f *PtypeFilter) Where(field string, values ...string) *PtypeFilter {
+	if f.err != nil {
+		return f
+	}
+	fields, err := fieldIndexes(f.model, f.ptype)
+	if err != nil {
+		f.err = err
+		return f
+	}
+	index, ok := fields[field]
+	if !ok {
+		names := make([]string, 0, len(fields))
+		for name := range fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		f.err = fmt.Errorf("unknown field %q of %s, known fields are %s", field, f.ptype, strings.Join(names, ", "))
+		return f
+	}
+	if index >= len(f.values) {
+		f.err = fmt.Errorf("field %q of %s is stored beyond column v7", field, f.ptype)
+		return f
+	}
+	f.values[index] = append(f.values[index], values...)
+	return f
+}
+
+// Build returns the filter of the ptype.
+func (f *PtypeFilter) Build() (Filter, error) {
+	if f.err != nil {
+		return Filter{}, f.err
+	}
+	return Filter{
+		Ptype: []string{f.ptype},
+		V0:    f.values[0],
+		V1:    f.values[1],
+		V2:    f.values[2],
+		V3:    f.values[3],
+		V4:    f.values[4],
+		V5:    f.values[5],
+		V6:    f.values[6],
+		V7:    f.values[7],
+	}, nil
+}
+
+// fieldIndexes returns the column index of every field name of ptype.
+// Policy fields are named by the policy definition, e.g. "dom" for "p = sub, dom, obj, act".
+// Role fields are named user, role and domain, plus any index set with SetFieldIndex.
+func fieldIndexes(m model.Model, ptype string) (map[string]int, error) {
+	fields := make(map[string]int)
+	if ast, ok := m["p"][ptype]; ok {
+		for i, token := range ast.Tokens {
+			fields[strings.TrimPrefix(token, ptype+"_")] = i
+		}
+		for name, index := range ast.FieldIndexMap {
+			fields[name] = index
+		}
+		return fields, nil
+	}
+	if ast, ok := m["g"][ptype]; ok {
+		for name, index := range roleFieldNames {
+			if index < len(ast.Tokens) {
+				fields[name] = index
+			}
+		}
+		for name, index := range ast.FieldIndexMap {
+			fields[name] = index
+		}
+		return fields, nil
+	}
+	return nil, fmt.Errorf("ptype %q is not defined in the model", ptype)
+}
@@ -0,0 +1,56 @@
+// Copyright 2025 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gormadapter
+
+import (
+	"testing"
+
+	"github.com/anzimu/casbin/v2"
+	fileadapter "github.com/anzimu/casbin/v2/persist/file-adapter"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterBuilder(t *testing.T) {
+	db := openSqliteTestDB(t)
+	a, err := NewAdapterByDBWithOptions(db, "", "", WithAutoMigrate())
+	assert.NoError(t, err)
+
+	e, err := casbin.NewEnforcer("examples/rbac_with_domains_model.conf", fileadapter.NewAdapter("examples/rbac_with_domains_policy.csv"))
+	assert.NoError(t, err)
+	assert.NoError(t, a.SavePolicy(e.GetModel()))
+	e.SetAdapter(a)
+
+	b := NewFilterBuilder(e.GetModel())
+	b.ForPtype("p").Where("dom", "domain1")
+	b.ForPtype("g").Where("domain", "domain1")
+	filters, err := b.Build()
+	assert.NoError(t, err)
+	assert.Equal(t, []Filter{{Ptype: []string{"p"}, V1: []string{"domain1"}}, {Ptype: []string{"g"}, V2: []string{"domain1"}}}, filters)
+
+	assert.NoError(t, e.LoadFilteredPolicy(filters))
+	testGetPolicy(t, e, [][]string{{"admin", "domain1", "data1", "read"}, {"admin", "domain1", "data1", "write"}})
+	assert.Equal(t, [][]string{{"alice", "admin", "domain1"}}, e.GetGroupingPolicy())
+
+	f, err := NewFilterBuilder(e.GetModel()).ForPtype("p").Where("sub", "admin").Where("act", "write").Build()
+	assert.NoError(t, err)
+	assert.Equal(t, Filter{Ptype: []string{"p"}, V0: []string{"admin"}, V3: []string{"write"}}, f)
+
+	_, err = NewFilterBuilder(e.GetModel()).ForPtype("p").Where("domain", "domain1").Build()
+	assert.Error(t, err)
+	_, err = NewFilterBuilder(e.GetModel()).ForPtype("p2").Where("dom", "domain1").Build()
+	assert.Error(t, err)
+	_, err = NewFilterBuilder(e.GetModel()).ForPtype("g").Where("dom", "domain1").Where("obj", "data1").Build()
+	assert.Error(t, err)
+}
@@ -0,0 +1,278 @@
+// Copyright 2025 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gormadapter
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrRegexNotSupported is returned when a Regex filter is built for a database other than MySQL and Postgres,
+// such as SQLite, which has no regular expression operator without an extension.
+var ErrRegexNotSupported = errors.New("[casbin] gorm adapter error: regular expressions are not supported by this database")
+
+// ruleColumns are the columns a FilterExpr may refer to.
+var ruleColumns = map[string]bool{
+	"ptype": true, "v0": true, "v1": true, "v2": true, "v3": true,
+	"v4": true, "v5": true, "v6": true, "v7": true,
+}
+
+// FilterExpr is a filter over the rule columns that is compiled into a parameterized WHERE clause.
+// It is accepted by LoadFilteredPolicy and RemoveFilteredPolicyByExpr.
+//
+// Example: all rules of p whose object starts with "/api/billing/", except those of "system:" subjects
+//
+//	And(Col("ptype").In("p"), Col("v1").Prefix("/api/billing/"), Not(Col("v0").Prefix("system:")))
+type FilterExpr interface {
+	build(db *gorm.DB) (clause.Expr, error)
+	// conditions returns the number of column conditions that restrict the matched rows.
+	conditions() int
+}
+
+type filterExprFunc struct {
+	fn    func(db *gorm.DB) (clause.Expr, error)
+	conds int
+}
+
+func (f filterExprFunc) build(db *gorm.DB) (clause.Expr, error) {
+	return f.fn(db)
+}
+
+func (f filterExprFunc) conditions() int {
+	return f.conds
+}
+
+// ColumnFilter builds the FilterExpr of a single rule column.
+type ColumnFilter struct {
+	name string
+}
+
+// Col returns the filter builder of column, one of "ptype" and "v0" to "v7".
+func Col(column string) ColumnFilter {
+	return ColumnFilter{name: strings.ToLower(column)}
+}
+
+func (c ColumnFilter) expr(fn func(column clause.Column) clause.Expr) filterExprFunc {
+	return filterExprFunc{conds: 1, fn: func(db *gorm.DB) (clause.Expr, error) {
+		if !ruleColumns[c.name] {
+			return clause.Expr{}, fmt.Errorf("unknown rule column %q in filter", c.name)
+		}
+		return fn(clause.Column{Name: c.name}), nil
+	}}
+}
+
+// In matches rows whose column is one of values.
+func (c ColumnFilter) In(values ...string) FilterExpr {
+	f := c.expr(func(column clause.Column) clause.Expr {
+		if len(values) == 0 {
+			return clause.Expr{SQL: "1 = 0"}
+		}
+		return clause.Expr{SQL: "? IN ?", Vars: []interface{}{column, values}}
+	})
+	if len(values) == 0 {
+		f.conds = 0
+	}
+	return f
+}
+
+// NotIn matches rows whose column is none of values.
+func (c ColumnFilter) NotIn(values ...string) FilterExpr {
+	f := c.expr(func(column clause.Column) clause.Expr {
+		if len(values) == 0 {
+			return clause.Expr{SQL: "1 = 1"}
+		}
+		return clause.Expr{SQL: "? NOT IN ?", Vars: []interface{}{column, values}}
+	})
+	if len(values) == 0 {
+		f.conds = 0
+	}
+	return f
+}
+
+// compare matches rows whose column compares to value with op.
+func (c ColumnFilter) compare(op string, value string) FilterExpr {
+	return c.expr(func(column clause.Column) clause.Expr {
+		return clause.Expr{SQL: "? " + op + " ?", Vars: []interface{}{column, value}}
+	})
+}
+
+// Gt matches rows whose column is greater than value. Columns are compared as strings,
+// in the collation of the database.
+func (c ColumnFilter) Gt(value string) FilterExpr {
+	return c.compare(">", value)
+}
+
+// Gte matches rows whose column is greater than or equal to value.
+func (c ColumnFilter) Gte(value string) FilterExpr {
+	return c.compare(">=", value)
+}
+
+// Lt matches rows whose column is less than value.
+func (c ColumnFilter) Lt(value string) FilterExpr {
+	return c.compare("<", value)
+}
+
+// Lte matches rows whose column is less than or equal to value.
+func (c ColumnFilter) Lte(value string) FilterExpr {
+	return c.compare("<=", value)
+}
+
+// Between matches rows whose column is between low and high, both included.
+func (c ColumnFilter) Between(low, high string) FilterExpr {
+	return c.expr(func(column clause.Column) clause.Expr {
+		return clause.Expr{SQL: "? BETWEEN ? AND ?", Vars: []interface{}{column, low, high}}
+	})
+}
+
+// likeEscaper escapes the LIKE wildcards of a literal, using "!" as escape character
+// because the meaning of a backslash differs between databases.
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+// Prefix matches rows whose column starts with prefix, taken literally.
+func (c ColumnFilter) Prefix(prefix string) FilterExpr {
+	return c.expr(func(column clause.Column) clause.Expr {
+		return clause.Expr{SQL: "? LIKE ? ESCAPE '!'", Vars: []interface{}{column, likeEscaper.Replace(prefix) + "%"}}
+	})
+}
+
+// Like matches rows whose column matches the SQL LIKE pattern.
+func (c ColumnFilter) Like(pattern string) FilterExpr {
+	return c.expr(func(column clause.Column) clause.Expr {
+		return clause.Expr{SQL: "? LIKE ?", Vars: []interface{}{column, pattern}}
+	})
+}
+
+// Regex matches rows whose column matches the regular expression.
+// It is supported on MySQL and Postgres only: on SQLite and other databases the filter
+// fails with ErrRegexNotSupported.
+func (c ColumnFilter) Regex(pattern string) FilterExpr {
+	inner := c.expr(func(column clause.Column) clause.Expr {
+		return clause.Expr{SQL: "?", Vars: []interface{}{column}}
+	})
+	return filterExprFunc{conds: 1, fn: func(db *gorm.DB) (clause.Expr, error) {
+		column, err := inner.build(db)
+		if err != nil {
+			return clause.Expr{}, err
+		}
+		switch db.Dialector.Name() {
+		case "postgres":
+			return clause.Expr{SQL: "? ~ ?", Vars: []interface{}{column, pattern}}, nil
+		case "mysql":
+			return clause.Expr{SQL: "? REGEXP ?", Vars: []interface{}{column, pattern}}, nil
+		default:
+			return clause.Expr{}, ErrRegexNotSupported
+		}
+	}}
+}
+
+// IsEmpty matches rows whose column is empty.
+func (c ColumnFilter) IsEmpty() FilterExpr {
+	return c.expr(func(column clause.Column) clause.Expr {
+		return clause.Expr{SQL: "(? = '' OR ? IS NULL)", Vars: []interface{}{column, column}}
+	})
+}
+
+// group joins exprs with op; an empty group evaluates to empty.
+func group(exprs []FilterExpr, op string, empty string) FilterExpr {
+	conds := 0
+	for _, e := range exprs {
+		if e != nil {
+			conds += e.conditions()
+		}
+	}
+	return filterExprFunc{conds: conds, fn: func(db *gorm.DB) (clause.Expr, error) {
+		if len(exprs) == 0 {
+			return clause.Expr{SQL: empty}, nil
+		}
+		parts := make([]string, 0, len(exprs))
+		vars := make([]interface{}, 0, len(exprs))
+		for _, e := range exprs {
+			if e == nil {
+				return clause.Expr{}, errors.New("nil filter expression")
+			}
+			expr, err := e.build(db)
+			if err != nil {
+				return clause.Expr{}, err
+			}
+			parts = append(parts, "?")
+			vars = append(vars, expr)
+		}
+		return clause.Expr{SQL: "(" + strings.Join(parts, " "+op+" ") + ")", Vars: vars}, nil
+	}}
+}
+
+// And matches rows that match all of exprs.
+func And(exprs ...FilterExpr) FilterExpr {
+	return group(exprs, "AND", "1 = 1")
+}
+
+// Or matches rows that match any of exprs.
+func Or(exprs ...FilterExpr) FilterExpr {
+	return group(exprs, "OR", "1 = 0")
+}
+
+// Not matches rows that do not match expr.
+func Not(expr FilterExpr) FilterExpr {
+	conds := 0
+	if expr != nil {
+		conds = expr.conditions()
+	}
+	return filterExprFunc{conds: conds, fn: func(db *gorm.DB) (clause.Expr, error) {
+		if expr == nil {
+			return clause.Expr{}, errors.New("nil filter expression")
+		}
+		inner, err := expr.build(db)
+		if err != nil {
+			return clause.Expr{}, err
+		}
+		return clause.Expr{SQL: "NOT (?)", Vars: []interface{}{inner}}, nil
+	}}
+}
+
+// exprQuery builds the gorm query to match the filter expression to use within a scope.
+func (a *Adapter) exprQuery(expr FilterExpr) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if expr == nil {
+			_ = db.AddError(errors.New("nil filter expression"))
+			return db
+		}
+		cond, err := expr.build(db)
+		if err != nil {
+			_ = db.AddError(err)
+			return db
+		}
+		return db.Where(cond)
+	}
+}
+
+// RemoveFilteredPolicyByExpr removes the policy rules that match the filter expression from the storage.
+// The expression must contain at least one column condition, so that And() or Col("v0").NotIn()
+// cannot wipe the table.
+func (a *Adapter) RemoveFilteredPolicyByExpr(expr FilterExpr) error {
+	return a.removeFilteredPolicyByExpr(a.db, expr)
+}
+
+// removeFilteredPolicyByExpr removes the policy rules that match the filter expression from the storage.
+func (a *Adapter) removeFilteredPolicyByExpr(db *gorm.DB, expr FilterExpr) error {
+	if expr == nil || expr.conditions() == 0 {
+		return errors.New("the filter expression has no column condition, please check")
+	}
+	defer a.markWrite()
+	return a.deleteRows(db.Scopes(a.ownRules(), a.exprQuery(expr)))
+}
@@ -0,0 +1,104 @@
+// Copyright 2025 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gormadapter
+
+import (
+	"testing"
+
+	"github.com/anzimu/casbin/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func initExprPolicy(t *testing.T) (*Adapter, *casbin.Enforcer) {
+	db := openSqliteTestDB(t)
+	a, err := NewAdapterByDBWithOptions(db, "", "", WithAutoMigrate())
+	assert.NoError(t, err)
+	assert.NoError(t, a.AddPolicies("p", "p", [][]string{
+		{"alice", "/api/billing/invoices", "read"},
+		{"bob", "/api/billing_x", "read"},
+		{"system:cron", "/api/billing/runs", "write"},
+		{"carol", "/api/users", "read"},
+		{"", "/api/users", "write"},
+	}))
+	e, _ := casbin.NewEnforcer("examples/rbac_model.conf")
+	e.SetAdapter(a)
+	return a, e
+}
+
+func TestLoadFilteredPolicyByExpr(t *testing.T) {
+	_, e := initExprPolicy(t)
+
+	// "_" of the prefix is taken literally, so "/api/billing_x" does not match "/api/billing/"
+	assert.NoError(t, e.LoadFilteredPolicy(Col("v1").Prefix("/api/billing/")))
+	testGetPolicy(t, e, [][]string{{"alice", "/api/billing/invoices", "read"}, {"system:cron", "/api/billing/runs", "write"}})
+
+	assert.NoError(t, e.LoadFilteredPolicy(And(Col("v1").Like("/api/billing%"), Not(Col("v0").Prefix("system:")))))
+	testGetPolicy(t, e, [][]string{{"alice", "/api/billing/invoices", "read"}, {"bob", "/api/billing_x", "read"}})
+
+	assert.NoError(t, e.LoadFilteredPolicy(Or(Col("v0").In("alice"), And(Col("v1").In("/api/users"), Col("v2").NotIn("read")))))
+	testGetPolicy(t, e, [][]string{{"alice", "/api/billing/invoices", "read"}, {"", "/api/users", "write"}})
+
+	assert.NoError(t, e.LoadFilteredPolicy(Col("v0").IsEmpty()))
+	testGetPolicy(t, e, [][]string{{"", "/api/users", "write"}})
+
+	assert.NoError(t, e.LoadFilteredPolicy(And(Col("ptype").In("p"), Col("v0").NotIn(), Or())))
+	testGetPolicy(t, e, [][]string{})
+
+	assert.Error(t, e.LoadFilteredPolicy(Col("v8").In("x")))
+	assert.Error(t, e.LoadFilteredPolicy(Col("id; drop table casbin_rule").In("x")))
+	assert.ErrorIs(t, e.LoadFilteredPolicy(Col("v0").Regex("^sys")), ErrRegexNotSupported)
+}
+
+func TestRemoveFilteredPolicyByExpr(t *testing.T) {
+	a, e := initExprPolicy(t)
+
+	assert.NoError(t, a.RemoveFilteredPolicyByExpr(And(Col("v1").Prefix("/api/billing"), Not(Col("v0").In("alice")))))
+	assert.NoError(t, e.LoadPolicy())
+	testGetPolicy(t, e, [][]string{{"alice", "/api/billing/invoices", "read"}, {"carol", "/api/users", "read"}, {"", "/api/users", "write"}})
+
+	// Expressions without a column condition would match every rule.
+	for _, expr := range []FilterExpr{nil, And(), Or(), Not(And()), Col("v0").NotIn(), And(Col("v0").In(), Or())} {
+		assert.Error(t, a.RemoveFilteredPolicyByExpr(expr))
+	}
+	assert.NoError(t, e.LoadPolicy())
+	assert.Len(t, e.GetPolicy(), 3)
+}
+
+func TestCompareFilterExpr(t *testing.T) {
+	_, e := initExprPolicy(t)
+
+	assert.NoError(t, e.LoadFilteredPolicy(Col("v0").Between("alice", "bob")))
+	testGetPolicy(t, e, [][]string{{"alice", "/api/billing/invoices", "read"}, {"bob", "/api/billing_x", "read"}})
+
+	assert.NoError(t, e.LoadFilteredPolicy(And(Col("v0").Gt("alice"), Col("v0").Lt("system"))))
+	testGetPolicy(t, e, [][]string{{"bob", "/api/billing_x", "read"}, {"carol", "/api/users", "read"}})
+
+	assert.NoError(t, e.LoadFilteredPolicy(And(Col("v0").Gte("carol"), Col("v1").Lte("/api/billing/runs"))))
+	testGetPolicy(t, e, [][]string{{"system:cron", "/api/billing/runs", "write"}})
+}
+
+func TestRegexFilterExpr(t *testing.T) {
+	db, recorder := openDryRunDB(t, "postgres")
+	a, err := NewAdapterByDBWithOptions(db, "", "")
+	assert.NoError(t, err)
+	assert.NoError(t, a.RemoveFilteredPolicyByExpr(Col("v0").Regex("^t1[0-9]{2}$")))
+	assert.Equal(t, []string{`DELETE FROM "casbin_rule" WHERE "v0" ~ '^t1[0-9]{2}$'`}, recorder.sqls)
+
+	db, recorder = openDryRunDB(t, "mysql")
+	a, err = NewAdapterByDBWithOptions(db, "", "")
+	assert.NoError(t, err)
+	assert.NoError(t, a.RemoveFilteredPolicyByExpr(Col("v0").Regex("^t1[0-9]{2}$")))
+	assert.Equal(t, []string{"DELETE FROM `casbin_rule` WHERE `v0` REGEXP '^t1[0-9]{2}$'"}, recorder.sqls)
+}
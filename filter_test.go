@@ -0,0 +1,94 @@
+// Copyright 2025 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gormadapter
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/anzimu/casbin/v2"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestBatchFilterWithoutDuplicates(t *testing.T) {
+	db := openSqliteTestDB(t)
+	a, err := NewAdapterByDBWithOptions(db, "", "", WithAutoMigrate())
+	assert.NoError(t, err)
+	initPolicy(t, a)
+
+	e, _ := casbin.NewEnforcer("examples/rbac_model.conf")
+	e.SetAdapter(a)
+	// bob's rule matches both filters and data2_admin's rules match the last two.
+	assert.NoError(t, e.LoadFilteredPolicy([]Filter{
+		{V0: []string{"bob"}},
+		{V1: []string{"data2"}},
+		{V0: []string{"data2_admin"}},
+	}))
+	testGetPolicy(t, e, [][]string{{"bob", "data2", "write"}, {"data2_admin", "data2", "read"}, {"data2_admin", "data2", "write"}})
+
+	// The same filters inside a transaction are queried one after another.
+	assert.NoError(t, db.Transaction(func(tx *gorm.DB) error {
+		b := *a
+		b.db = tx
+		e.ClearPolicy()
+		return b.LoadFilteredPolicy(e.GetModel(), []Filter{{V1: []string{"data1"}}, {V0: []string{"alice"}}})
+	}))
+	testGetPolicy(t, e, [][]string{{"alice", "data1", "read"}})
+}
+
+func TestBatchFilterParallelism(t *testing.T) {
+	db := openSqliteTestDB(t)
+	a, err := NewAdapterByDBWithOptions(db, "", "", WithFilterParallelism(2), WithAutoMigrate())
+	assert.NoError(t, err)
+	initPolicy(t, a)
+
+	// Hold the queries until two of them run at the same time.
+	var running, maxRunning atomic.Int32
+	concurrent := make(chan struct{})
+	var once sync.Once
+	assert.NoError(t, db.Callback().Query().Before("gorm:query").Register("test:barrier", func(db *gorm.DB) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			m := maxRunning.Load()
+			if n <= m || maxRunning.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		if n >= 2 {
+			once.Do(func() { close(concurrent) })
+		}
+		select {
+		case <-concurrent:
+		case <-time.After(5 * time.Second):
+		}
+	}))
+
+	filters := []Filter{{V0: []string{"alice"}}, {V0: []string{"bob"}}, {V1: []string{"data2"}}, {V2: []string{"read"}}}
+	e, _ := casbin.NewEnforcer("examples/rbac_model.conf")
+	assert.NoError(t, a.LoadFilteredPolicy(e.GetModel(), filters))
+
+	testGetPolicy(t, e, [][]string{{"alice", "data1", "read"}, {"bob", "data2", "write"}, {"data2_admin", "data2", "read"}, {"data2_admin", "data2", "write"}})
+	// The queries ran concurrently, but never more than two at a time.
+	select {
+	case <-concurrent:
+	default:
+		t.Error("the filter queries did not run concurrently")
+	}
+	assert.Equal(t, int32(2), maxRunning.Load())
+}
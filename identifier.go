@@ -0,0 +1,80 @@
+// Copyright 2025 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gormadapter
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrInvalidIdentifier = errors.New("[casbin] gorm adapter error: invalid SQL identifier")
+
+// identifierPattern matches an identifier that is safe once quoted by the dialect: every identifier is
+// quoted in the generated SQL, so names such as "casbin-rule" or "2024_rules" are accepted, and only
+// whitespace, control characters, quotes, backslashes, semicolons and dots are rejected.
+// The length limit is left to the database.
+var identifierPattern = regexp.MustCompile("^[^\\s\\p{C}\"'`\\\\;.]+$")
+
+// validateIdentifier makes sure name can be used as a column or index name.
+func validateIdentifier(name string) error {
+	if !identifierPattern.MatchString(name) {
+		return fmt.Errorf("%w: %q", ErrInvalidIdentifier, name)
+	}
+	return nil
+}
+
+// validateTableName makes sure name can be used as a table name.
+// A schema-qualified name such as "authz.casbin_rule" is accepted.
+func validateTableName(name string) error {
+	parts := strings.Split(name, ".")
+	if len(parts) > 2 {
+		return fmt.Errorf("%w: %q", ErrInvalidIdentifier, name)
+	}
+	for _, part := range parts {
+		if !identifierPattern.MatchString(part) {
+			return fmt.Errorf("%w: %q", ErrInvalidIdentifier, name)
+		}
+	}
+	return nil
+}
+
+// ruleIndexName returns the name of the unique index of tableName.
+// The schema of a qualified table name is folded into the index name.
+func ruleIndexName(tableName string) string {
+	return strings.ReplaceAll("idx_"+tableName, ".", "_")
+}
+
+// ruleIndexColumns returns the columns of the unique rule index, quoted when used as a SQL var.
+func (a *Adapter) ruleIndexColumns() []interface{} {
+	columns := make([]interface{}, 0, 10)
+	if a.tenantColumn != "" {
+		columns = append(columns, clause.Column{Name: a.tenantColumn})
+	}
+	for _, name := range []string{"ptype", "v0", "v1", "v2", "v3", "v4", "v5", "v6", "v7"} {
+		columns = append(columns, clause.Column{Name: name})
+	}
+	return columns
+}
+
+func isSqlite(db *gorm.DB) bool {
+	name := db.Config.Name()
+	return name == sqlite.DriverName || name == "sqlite3"
+}
@@ -0,0 +1,114 @@
+// Copyright 2025 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gormadapter
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/anzimu/casbin/v2"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// sqlRecorder is a gorm logger that records every statement, including DryRun ones.
+type sqlRecorder struct {
+	mu   sync.Mutex
+	sqls []string
+}
+
+func (r *sqlRecorder) LogMode(logger.LogLevel) logger.Interface      { return r }
+func (r *sqlRecorder) Info(context.Context, string, ...interface{})  {}
+func (r *sqlRecorder) Warn(context.Context, string, ...interface{})  {}
+func (r *sqlRecorder) Error(context.Context, string, ...interface{}) {}
+func (r *sqlRecorder) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	sql, _ := fc()
+	r.mu.Lock()
+	r.sqls = append(r.sqls, sql)
+	r.mu.Unlock()
+}
+
+// openDryRunDB opens a gorm instance of the given dialect that never talks to a database.
+func openDryRunDB(t *testing.T, dialect string) (*gorm.DB, *sqlRecorder) {
+	var dialector gorm.Dialector
+	switch dialect {
+	case "postgres":
+		dialector = postgres.New(postgres.Config{DSN: "host=127.0.0.1 user=casbin dbname=casbin sslmode=disable"})
+	case "mysql":
+		dialector = mysql.New(mysql.Config{DSN: "root:@tcp(127.0.0.1:3306)/casbin", SkipInitializeWithVersion: true})
+	}
+	recorder := &sqlRecorder{}
+	db, err := gorm.Open(dialector, &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true, Logger: recorder})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db, recorder
+}
+
+func TestTableNameValidation(t *testing.T) {
+	db := openSqliteTestDB(t)
+
+	for _, name := range []string{"casbin_rule; drop table users", "casbin rule", "a.b.c", "x`y", "x\"y", "x'y", "x\\y", "x\ny"} {
+		_, err := NewAdapterByDBUseTableName(db, "", name, nil)
+		assert.ErrorIs(t, err, ErrInvalidIdentifier, name)
+		_, err = NewFilteredAdapterByDB(db, "", name)
+		assert.ErrorIs(t, err, ErrInvalidIdentifier, name)
+	}
+	_, err := NewAdapterByDBUseTableName(db, "cms;", "casbin", nil)
+	assert.ErrorIs(t, err, ErrInvalidIdentifier)
+	_, err = NewAdapterByDBWithOptions(db, "", "", WithTenant("tenant id", FixedTenant("t1")))
+	assert.ErrorIs(t, err, ErrInvalidIdentifier)
+
+	// Names that need quoting are accepted, as they were before the validation.
+	for _, name := range []string{"casbin-rule", "1abc", "rules_" + strings.Repeat("x", 70)} {
+		a, err := NewAdapterByDBUseTableName(db, "cms-v2", name, nil, true)
+		assert.NoError(t, err, name)
+		testSaveLoad(t, a)
+	}
+
+	// sqlite accepts the "main" schema, which exercises schema-qualified names.
+	a, err := NewAdapterByDBUseTableName(db, "", "main.casbin_rule", nil, true)
+	assert.NoError(t, err)
+	testSaveLoad(t, a)
+	e, _ := casbin.NewEnforcer("examples/rbac_model.conf", a)
+	assert.NoError(t, e.SavePolicy())
+	testGetPolicy(t, e, [][]string{{"alice", "data1", "read"}, {"bob", "data2", "write"}, {"data2_admin", "data2", "read"}, {"data2_admin", "data2", "write"}})
+}
+
+func TestQuotedIdentifiers(t *testing.T) {
+	db, recorder := openDryRunDB(t, "postgres")
+	a, err := NewAdapterByDBUseTableName(db, "", "authz.casbin_rule", nil)
+	assert.NoError(t, err)
+	assert.NoError(t, a.truncateTable(db))
+	assert.Equal(t, []string{`truncate table "authz"."casbin_rule" RESTART IDENTITY`}, recorder.sqls)
+
+	db, recorder = openDryRunDB(t, "mysql")
+	a, err = NewAdapterByDBUseTableName(db, "cms", "casbin", nil)
+	assert.NoError(t, err)
+	assert.NoError(t, a.truncateTable(db))
+	assert.Equal(t, []string{"truncate table `cms_casbin`"}, recorder.sqls)
+
+	db, recorder = openDryRunDB(t, "postgres")
+	a, err = NewAdapterByDBUseTableName(db, "cms-v2", "casbin", nil)
+	assert.NoError(t, err)
+	assert.NoError(t, a.truncateTable(db))
+	assert.Equal(t, []string{`truncate table "cms-v2_casbin" RESTART IDENTITY`}, recorder.sqls)
+}
@@ -2,15 +2,24 @@ package gormadapter
 
 import (
 	"errors"
+	"sort"
+	"sync"
+	"time"
+
 	"github.com/anzimu/casbin/v2/model"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"gorm.io/plugin/dbresolver"
 )
 
 // loadPolicy loads policy from database.
 func (a *Adapter) loadPolicy(db *gorm.DB, model model.Model) error {
+	if a.loadPageSize > 0 {
+		return a.loadPolicyPaged(db, model)
+	}
+
 	var lines []CasbinRule
-	if err := db.Scopes(a.casbinRuleTable()).Order("ID").Find(&lines).Error; err != nil {
+	if err := db.Scopes(a.casbinRuleTable(), a.readScope(), a.loadScope()).Order("ID").Find(&lines).Error; err != nil {
 		return err
 	}
 	err := a.Preview(&lines, model)
@@ -27,10 +36,48 @@ func (a *Adapter) loadPolicy(db *gorm.DB, model model.Model) error {
 	return nil
 }
 
+// loadScope restricts the rules loaded into a model.
+func (a *Adapter) loadScope() func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if len(a.loadSources) > 0 {
+			db = db.Where(clause.IN{Column: clause.Column{Name: sourceColumn}, Values: toInterfaces(a.loadSources)})
+		}
+		return a.validityScope(db, time.Now())
+	}
+}
+
+// loadPolicyPaged loads policy from database in pages of a.loadPageSize rows ordered by ID,
+// so that only one page is held in memory at a time.
+// Unlike loadPolicy, a failing row only rolls back the load of its own page.
+func (a *Adapter) loadPolicyPaged(db *gorm.DB, model model.Model) error {
+	var lastID uint
+	for {
+		var lines []CasbinRule
+		if err := db.Scopes(a.casbinRuleTable(), a.readScope(), a.loadScope()).Where("id > ?", lastID).Order("id").Limit(a.loadPageSize).Find(&lines).Error; err != nil {
+			return err
+		}
+		if len(lines) == 0 {
+			return nil
+		}
+		lastID = lines[len(lines)-1].ID
+		full := len(lines) == a.loadPageSize
+
+		if err := a.Preview(&lines, model); err != nil {
+			return err
+		}
+		for _, line := range lines {
+			if err := loadPolicyLine(line, model); err != nil {
+				return err
+			}
+		}
+		if !full {
+			return nil
+		}
+	}
+}
+
 // loadFilteredPolicy loads only policy rules that match the filter.
 func (a *Adapter) loadFilteredPolicy(db *gorm.DB, model model.Model, filter interface{}) error {
-	var lines []CasbinRule
-
 	batchFilter := BatchFilter{
 		filters: []Filter{},
 	}
@@ -45,33 +92,99 @@ func (a *Adapter) loadFilteredPolicy(db *gorm.DB, model model.Model, filter inte
 		batchFilter = filterValue
 	case *BatchFilter:
 		batchFilter = *filterValue
-	default:
-		return errors.New("unsupported filter type")
-	}
-
-	for _, f := range batchFilter.filters {
-		if err := db.Scopes(a.casbinRuleTable()).Scopes(a.filterQuery(a.db, f)).Order("ID").Find(&lines).Error; err != nil {
+	case FilterExpr:
+		var lines []CasbinRule
+		if err := db.Scopes(a.casbinRuleTable(), a.readScope(), a.loadScope(), a.exprQuery(filterValue)).Order("ID").Find(&lines).Error; err != nil {
 			return err
 		}
-
 		for _, line := range lines {
-			err := loadPolicyLine(line, model)
-			if err != nil {
+			if err := loadPolicyLine(line, model); err != nil {
 				return err
 			}
 		}
+		a.isFiltered = true
+		return nil
+	default:
+		return errors.New("unsupported filter type")
+	}
+
+	lines, err := a.queryFilters(db, batchFilter.filters)
+	if err != nil {
+		return err
+	}
+	for _, line := range lines {
+		if err := loadPolicyLine(line, model); err != nil {
+			return err
+		}
 	}
 	a.isFiltered = true
 
 	return nil
 }
 
+// queryFilters returns the rows matching any of the filters, without duplicates and ordered by ID.
+// The filters are queried concurrently, at most a.filterParallelism at a time,
+// except within a transaction whose single connection cannot be shared.
+func (a *Adapter) queryFilters(db *gorm.DB, filters []Filter) ([]CasbinRule, error) {
+	parallelism := a.filterParallelism
+	if parallelism <= 0 {
+		parallelism = defaultFilterParallelism
+	}
+	if _, ok := db.Statement.ConnPool.(gorm.TxCommitter); ok {
+		parallelism = 1
+	}
+
+	results := make([][]CasbinRule, len(filters))
+	errs := make([]error, len(filters))
+	sem := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+	for i, f := range filters {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, f Filter) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			// Each goroutine builds its query on its own statement.
+			tx := db.Session(&gorm.Session{})
+			errs[i] = tx.Scopes(a.casbinRuleTable(), a.readScope(), a.loadScope()).Scopes(a.filterQuery(tx, f)).Order("ID").Find(&results[i]).Error
+		}(i, f)
+	}
+	wg.Wait()
+
+	seen := make(map[uint]struct{})
+	lines := make([]CasbinRule, 0)
+	for i, result := range results {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		for _, line := range result {
+			if _, ok := seen[line.ID]; ok {
+				continue
+			}
+			seen[line.ID] = struct{}{}
+			lines = append(lines, line)
+		}
+	}
+	sort.Slice(lines, func(i, j int) bool { return lines[i].ID < lines[j].ID })
+	return lines, nil
+}
+
 // savePolicy saves policy to database.
 func (a *Adapter) savePolicy(db *gorm.DB, model model.Model) error {
+	defer a.markWrite()
 	var err error
 	tx := db.Scopes(a.casbinRuleTable()).Clauses(dbresolver.Write).Begin()
 
-	err = a.truncateTable(db)
+	// Read what must survive the truncate before wiping the table.
+	snapshot, err := a.snapshotRules(db, time.Now())
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = a.truncateRules(db, snapshot.keep)
 
 	if err != nil {
 		tx.Rollback()
@@ -82,9 +195,13 @@ func (a *Adapter) savePolicy(db *gorm.DB, model model.Model) error {
 	flushEvery := 1000
 	for ptype, ast := range model["p"] {
 		for _, rule := range ast.Policy {
-			lines = append(lines, a.savePolicyLine(ptype, rule))
+			line := a.savePolicyLine(ptype, rule)
+			if snapshot.skip[ruleKey(line)] {
+				continue
+			}
+			lines = append(lines, line)
 			if len(lines) > flushEvery {
-				if err := tx.Create(&lines).Error; err != nil {
+				if err := a.createLinesEach(tx, lines, snapshot.extra); err != nil {
 					tx.Rollback()
 					return err
 				}
@@ -95,9 +212,13 @@ func (a *Adapter) savePolicy(db *gorm.DB, model model.Model) error {
 
 	for ptype, ast := range model["g"] {
 		for _, rule := range ast.Policy {
-			lines = append(lines, a.savePolicyLine(ptype, rule))
+			line := a.savePolicyLine(ptype, rule)
+			if snapshot.skip[ruleKey(line)] {
+				continue
+			}
+			lines = append(lines, line)
 			if len(lines) > flushEvery {
-				if err := tx.Create(&lines).Error; err != nil {
+				if err := a.createLinesEach(tx, lines, snapshot.extra); err != nil {
 					tx.Rollback()
 					return err
 				}
@@ -106,7 +227,7 @@ func (a *Adapter) savePolicy(db *gorm.DB, model model.Model) error {
 		}
 	}
 	if len(lines) > 0 {
-		if err := tx.Create(&lines).Error; err != nil {
+		if err := a.createLinesEach(tx, lines, snapshot.extra); err != nil {
 			tx.Rollback()
 			return err
 		}
@@ -116,33 +237,174 @@ func (a *Adapter) savePolicy(db *gorm.DB, model model.Model) error {
 	return err
 }
 
+// savedRule is a stored rule with the optional columns that savePolicy must keep.
+type savedRule struct {
+	CasbinRule
+	Source    string
+	ValidFrom *time.Time
+	ExpiresAt *time.Time
+	Declared  int
+}
+
+// ruleSnapshot is what savePolicy reads before it wipes the rule table.
+type ruleSnapshot struct {
+	// skip are the keys of the rules of the other sources, which stay in the table and must not be written again.
+	skip map[string]bool
+	// keep are the IDs of the rules of the adapter that the truncate must not delete: with WithValidity,
+	// the rules that are not valid now, which are not in the model. If the model has them anyway,
+	// e.g. after an enforcer's AddPolicy without auto-save, they are replaced when the model is written.
+	keep []uint
+	// columns are the values of the optional columns of the rewritten rules by rule key.
+	columns map[string]map[string]interface{}
+	// defaults are the values of the optional columns of the new rules.
+	defaults map[string]interface{}
+}
+
+// snapshotRules reads the rules that savePolicy must keep or write back with their optional columns.
+func (a *Adapter) snapshotRules(db *gorm.DB, now time.Time) (*ruleSnapshot, error) {
+	snapshot := &ruleSnapshot{skip: map[string]bool{}, columns: map[string]map[string]interface{}{}}
+	if a.validity {
+		snapshot.defaults = map[string]interface{}{validFromColumn: nil, expiresAtColumn: nil}
+	}
+	// The declared marker of SyncDeclaredPolicies must survive, or the rules could no longer be pruned.
+	declared := primary(a.db).Migrator().HasColumn(a.getFullTableName(), declaredColumn)
+	if declared {
+		if snapshot.defaults == nil {
+			snapshot.defaults = map[string]interface{}{}
+		}
+		snapshot.defaults[declaredColumn] = 0
+	}
+	if a.sourceName == "" && snapshot.defaults == nil {
+		return snapshot, nil
+	}
+
+	var rules []savedRule
+	if err := primary(db).Scopes(a.casbinRuleTable()).Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	for _, rule := range rules {
+		key := ruleKey(rule.CasbinRule)
+		switch {
+		case a.sourceName != "" && rule.Source != a.sourceName:
+			snapshot.skip[key] = true
+		case a.validity && !validAt(rule, now):
+			snapshot.keep = append(snapshot.keep, rule.ID)
+		case snapshot.defaults != nil:
+			columns := make(map[string]interface{}, len(snapshot.defaults))
+			if a.validity {
+				columns[validFromColumn], columns[expiresAtColumn] = rule.ValidFrom, rule.ExpiresAt
+			}
+			if declared {
+				columns[declaredColumn] = rule.Declared
+			}
+			snapshot.columns[key] = columns
+		}
+	}
+	return snapshot, nil
+}
+
+// extra returns the optional columns to write with line.
+func (s *ruleSnapshot) extra(line CasbinRule) map[string]interface{} {
+	if columns, ok := s.columns[ruleKey(line)]; ok {
+		return columns
+	}
+	return s.defaults
+}
+
+// validAt reports whether the validity bounds of rule contain now, like validityScope.
+func validAt(rule savedRule, now time.Time) bool {
+	return (rule.ValidFrom == nil || !rule.ValidFrom.After(now)) && (rule.ExpiresAt == nil || rule.ExpiresAt.After(now))
+}
+
 // addPolicy adds a policy rule to the storage.
 func (a *Adapter) addPolicy(db *gorm.DB, sec string, ptype string, rule []string) error {
+	defer a.markWrite()
 	line := a.savePolicyLine(ptype, rule)
-	err := db.Scopes(a.casbinRuleTable()).Create(&line).Error
+	err := a.createLines(db.Scopes(a.casbinRuleTable()), []CasbinRule{line})
 	return err
 }
 
 // addPolicies adds multiple policy rules to the storage.
 func (a *Adapter) addPolicies(db *gorm.DB, sec string, ptype string, rules [][]string) error {
+	defer a.markWrite()
 	var lines []CasbinRule
 	for _, rule := range rules {
 		line := a.savePolicyLine(ptype, rule)
 		lines = append(lines, line)
 	}
-	return db.Scopes(a.casbinRuleTable()).Create(&lines).Error
+	return a.createLines(db.Scopes(a.casbinRuleTable()), lines)
+}
+
+// createLines inserts lines through db, stamping the tenant column when the adapter is multi-tenant.
+func (a *Adapter) createLines(db *gorm.DB, lines []CasbinRule) error {
+	return a.createLinesWith(db, lines, nil)
+}
+
+// createLinesWith inserts lines with the values of the extra columns.
+func (a *Adapter) createLinesWith(db *gorm.DB, lines []CasbinRule, extra map[string]interface{}) error {
+	if len(extra) == 0 {
+		return a.createLinesEach(db, lines, nil)
+	}
+	return a.createLinesEach(db, lines, func(CasbinRule) map[string]interface{} { return extra })
+}
+
+// dropStaleCopies deletes the soft-deleted and the invalid copies of lines before lines are written.
+func (a *Adapter) dropStaleCopies(db *gorm.DB, lines []CasbinRule) error {
+	if err := a.dropDeletedCopies(db, lines); err != nil {
+		return err
+	}
+	return a.dropInvalidCopies(db, lines)
+}
+
+// createLinesEach inserts lines with the values of the extra columns of each line,
+// which must set the same columns for all the lines.
+func (a *Adapter) createLinesEach(db *gorm.DB, lines []CasbinRule, extra func(line CasbinRule) map[string]interface{}) error {
+	if err := a.dropStaleCopies(db, lines); err != nil {
+		return err
+	}
+	if a.tenantColumn == "" && a.sourceName == "" && extra == nil {
+		return a.write(db, OperationCreate, lines, func(db *gorm.DB) *gorm.DB {
+			return db.Create(&lines)
+		}).Error
+	}
+
+	tenant, err := a.resolveTenant(db)
+	if err != nil {
+		return err
+	}
+	rows := make([]map[string]interface{}, 0, len(lines))
+	for _, line := range lines {
+		row := line.toMap()
+		if a.tenantColumn != "" {
+			row[a.tenantColumn] = tenant
+		}
+		if a.sourceName != "" {
+			row[sourceColumn] = a.sourceName
+		}
+		if extra != nil {
+			for column, value := range extra(line) {
+				row[column] = value
+			}
+		}
+		rows = append(rows, row)
+	}
+	return a.write(db, OperationCreate, lines, func(db *gorm.DB) *gorm.DB {
+		return db.Create(&rows)
+	}).Error
 }
 
 // removePolicy removes a policy rule from the storage.
 func (a *Adapter) removePolicy(db *gorm.DB, sec string, ptype string, rule []string) error {
+	defer a.markWrite()
 	line := a.savePolicyLine(ptype, rule)
-	err := a.rawDelete(db, line) //can't use db.Delete as we're not using primary key https://gorm.io/docs/update.html
+	err := a.rawDelete(db.Scopes(a.ownRules()), line) //can't use db.Delete as we're not using primary key https://gorm.io/docs/update.html
 	return err
 }
 
 // removePolicies removes multiple policy rules from the storage.
 func (a *Adapter) removePolicies(db *gorm.DB, sec string, ptype string, rules [][]string) error {
-	return db.Scopes(a.casbinRuleTable()).Transaction(func(tx *gorm.DB) error {
+	defer a.markWrite()
+	return db.Scopes(a.ownRules()).Transaction(func(tx *gorm.DB) error {
 		for _, rule := range rules {
 			line := a.savePolicyLine(ptype, rule)
 			if err := a.rawDelete(tx, line); err != nil { //can't use db.Delete as we're not using primary key https://gorm.io/docs/update.html
@@ -154,8 +416,9 @@ func (a *Adapter) removePolicies(db *gorm.DB, sec string, ptype string, rules []
 
 // removeFilteredPolicy removes policy rules that match the filter from the storage.
 func (a *Adapter) removeFilteredPolicy(db *gorm.DB, sec string, ptype string, fieldIndex int, fieldValues ...string) error {
+	defer a.markWrite()
 	line := a.getTableInstance()
-	tx := db.Scopes(a.casbinRuleTable())
+	tx := db.Scopes(a.ownRules())
 
 	line.Ptype = ptype
 
@@ -198,12 +461,20 @@ func (a *Adapter) removeFilteredPolicy(db *gorm.DB, sec string, ptype string, fi
 
 // updatePolicy updates a new policy rule to DB.
 func (a *Adapter) updatePolicy(db *gorm.DB, sec string, ptype string, oldRule, newPolicy []string) error {
+	defer a.markWrite()
 	oldLine := a.savePolicyLine(ptype, oldRule)
 	newLine := a.savePolicyLine(ptype, newPolicy)
-	return db.Scopes(a.casbinRuleTable()).Model(&oldLine).Where(&oldLine).Updates(newLine).Error
+	if err := a.dropStaleCopies(db, []CasbinRule{newLine}); err != nil {
+		return err
+	}
+	query := db.Scopes(a.ownRules()).Model(&oldLine).Where(&oldLine)
+	return a.write(query, OperationUpdate, nil, func(db *gorm.DB) *gorm.DB {
+		return db.Updates(newLine)
+	}).Error
 }
 
 func (a *Adapter) updatePolicies(db *gorm.DB, sec string, ptype string, oldRules, newRules [][]string) error {
+	defer a.markWrite()
 	oldPolicies := make([]CasbinRule, 0, len(oldRules))
 	newPolicies := make([]CasbinRule, 0, len(oldRules))
 	for _, oldRule := range oldRules {
@@ -212,9 +483,15 @@ func (a *Adapter) updatePolicies(db *gorm.DB, sec string, ptype string, oldRules
 	for _, newRule := range newRules {
 		newPolicies = append(newPolicies, a.savePolicyLine(ptype, newRule))
 	}
-	err := db.Scopes(a.casbinRuleTable()).Transaction(func(tx *gorm.DB) error {
+	err := db.Scopes(a.ownRules()).Transaction(func(tx *gorm.DB) error {
+		if err := a.dropStaleCopies(tx, newPolicies); err != nil {
+			return err
+		}
 		for i := range oldPolicies {
-			if err := tx.Model(&oldPolicies[i]).Where(&oldPolicies[i]).Updates(newPolicies[i]).Error; err != nil {
+			query := tx.Model(&oldPolicies[i]).Where(&oldPolicies[i])
+			if err := a.write(query, OperationUpdate, nil, func(db *gorm.DB) *gorm.DB {
+				return db.Updates(newPolicies[i])
+			}).Error; err != nil {
 				return err
 			}
 		}
@@ -225,6 +502,7 @@ func (a *Adapter) updatePolicies(db *gorm.DB, sec string, ptype string, oldRules
 
 // UpdateFilteredPolicies deletes old rules and adds new rules.
 func (a *Adapter) updateFilteredPolicies(db *gorm.DB, sec string, ptype string, newPolicies [][]string, fieldIndex int, fieldValues ...string) ([][]string, error) {
+	defer a.markWrite()
 	line := a.getTableInstance()
 
 	line.Ptype = ptype
@@ -259,18 +537,18 @@ func (a *Adapter) updateFilteredPolicies(db *gorm.DB, sec string, ptype string,
 		newP = append(newP, a.savePolicyLine(ptype, newRule))
 	}
 
-	tx := db.Scopes(a.casbinRuleTable()).Begin()
+	tx := db.Scopes(a.ownRules()).Begin()
 	str, args := line.queryString()
 	if err := tx.Where(str, args...).Find(&oldP).Error; err != nil {
 		tx.Rollback()
 		return nil, err
 	}
-	if err := tx.Where(str, args...).Delete([]CasbinRule{}).Error; err != nil {
+	if err := a.deleteRows(tx.Where(str, args...)); err != nil {
 		tx.Rollback()
 		return nil, err
 	}
 	for i := range newP {
-		if err := tx.Create(&newP[i]).Error; err != nil {
+		if err := a.createLines(tx, newP[i:i+1]); err != nil {
 			tx.Rollback()
 			return nil, err
 		}
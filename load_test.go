@@ -0,0 +1,123 @@
+// Copyright 2025 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gormadapter
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/anzimu/casbin/v2"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// seedRoleLinks inserts n "g" rules straight into the rule table.
+func seedRoleLinks(tb testing.TB, db *gorm.DB, n int) {
+	lines := make([]CasbinRule, 0, n)
+	for i := 0; i < n; i++ {
+		lines = append(lines, CasbinRule{Ptype: "g", V0: fmt.Sprintf("user%d", i), V1: fmt.Sprintf("role%d", i%100)})
+	}
+	if err := db.CreateInBatches(lines, 500).Error; err != nil {
+		tb.Fatal(err)
+	}
+}
+
+func TestLoadPolicyPaged(t *testing.T) {
+	db := openSqliteTestDB(t)
+	a, err := NewAdapterByDBWithOptions(db, "", "", WithLoadPageSize(2), WithAutoMigrate())
+	assert.NoError(t, err)
+	initPolicy(t, a)
+
+	// page boundaries that split, match and exceed the row count
+	for _, size := range []int{1, 2, 4, 5, 100} {
+		a.loadPageSize = size
+		e, _ := casbin.NewEnforcer("examples/rbac_model.conf", a)
+		testGetPolicy(t, e, [][]string{{"alice", "data1", "read"}, {"bob", "data2", "write"}, {"data2_admin", "data2", "read"}, {"data2_admin", "data2", "write"}})
+	}
+
+	_, err = NewAdapterByDBWithOptions(db, "", "", WithLoadPageSize(0))
+	assert.Error(t, err)
+}
+
+// peakHeap samples the heap while fn runs and returns the highest HeapAlloc seen.
+func peakHeap(fn func()) uint64 {
+	var peak atomic.Uint64
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	sample := func() {
+		var m runtime.MemStats
+		runtime.ReadMemStats(&m)
+		if m.HeapAlloc > peak.Load() {
+			peak.Store(m.HeapAlloc)
+		}
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				sample()
+			}
+		}
+	}()
+	fn()
+	close(done)
+	wg.Wait()
+	sample()
+	return peak.Load()
+}
+
+func benchmarkLoadPolicy(b *testing.B, opts ...Option) {
+	db := openSqliteTestDB(b)
+	a, err := NewAdapterByDBWithOptions(db, "", "", append(opts, WithAutoMigrate())...)
+	if err != nil {
+		b.Fatal(err)
+	}
+	seedRoleLinks(b, db, 20000)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	var peak uint64
+	for i := 0; i < b.N; i++ {
+		e, _ := casbin.NewEnforcer("examples/rbac_model.conf")
+		runtime.GC()
+		if p := peakHeap(func() {
+			if err := a.LoadPolicy(e.GetModel()); err != nil {
+				b.Fatal(err)
+			}
+		}); p > peak {
+			peak = p
+		}
+	}
+	b.ReportMetric(float64(peak), "peak-heap-B")
+}
+
+func BenchmarkLoadPolicy(b *testing.B) {
+	benchmarkLoadPolicy(b)
+}
+
+func BenchmarkLoadPolicyPaged(b *testing.B) {
+	benchmarkLoadPolicy(b, WithLoadPageSize(1000))
+}
@@ -0,0 +1,114 @@
+// Copyright 2025 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gormadapter
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// Option configures optional behaviour of an Adapter.
+type Option func(a *Adapter) error
+
+// WithAutoMigrate creates the rule table (and any columns required by other options) when the adapter is built.
+func WithAutoMigrate() Option {
+	return func(a *Adapter) error {
+		a.autoMigrate = true
+		return nil
+	}
+}
+
+// createOptionalColumns adds the columns required by the options to the rule table.
+func (a *Adapter) createOptionalColumns() error {
+	for _, create := range []func() error{a.createTenantColumn, a.createSourceColumn, a.createValidityColumns, a.createSoftDeleteColumn,
+		a.createDeclaredColumn} {
+		if err := create(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WithSchema places the rule table in the given schema, e.g. a Postgres schema.
+// With WithAutoMigrate the schema is created if it does not exist.
+func WithSchema(schema string) Option {
+	return func(a *Adapter) error {
+		if err := validateIdentifier(schema); err != nil {
+			return err
+		}
+		a.schema = schema
+		return validateTableName(a.getFullTableName())
+	}
+}
+
+// WithLoadPageSize makes LoadPolicy read the rule table in ID ordered pages of size rows
+// instead of all at once, which bounds the memory needed to load a large policy.
+func WithLoadPageSize(size int) Option {
+	return func(a *Adapter) error {
+		if size <= 0 {
+			return errors.New("load page size must be positive")
+		}
+		a.loadPageSize = size
+		return nil
+	}
+}
+
+// WithFilterParallelism sets how many filters of a BatchFilter LoadFilteredPolicy queries at the same time.
+func WithFilterParallelism(n int) Option {
+	return func(a *Adapter) error {
+		if n <= 0 {
+			return errors.New("filter parallelism must be positive")
+		}
+		a.filterParallelism = n
+		return nil
+	}
+}
+
+// NewAdapterByDBWithOptions creates gorm-adapter by an existing Gorm instance, the specified table prefix and table name,
+// and applies the given options.
+// Example: gormadapter.NewAdapterByDBWithOptions(db, "", "casbin_rule", gormadapter.WithAutoMigrate())
+func NewAdapterByDBWithOptions(db *gorm.DB, prefix string, tableName string, opts ...Option) (*Adapter, error) {
+	if len(tableName) == 0 {
+		tableName = defaultTableName
+	}
+
+	a := &Adapter{
+		tablePrefix: prefix,
+		tableName:   tableName,
+		db:          db,
+	}
+	if err := validateTableName(a.getFullTableName()); err != nil {
+		return nil, err
+	}
+
+	for _, opt := range opts {
+		if err := opt(a); err != nil {
+			return nil, err
+		}
+	}
+
+	// A dry-run adapter never changes the database, not even its schema.
+	if a.autoMigrate && a.dryRun == nil {
+		if err := a.createTable(); err != nil {
+			return nil, err
+		}
+	}
+	if err := a.registerReplicas(); err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
@@ -0,0 +1,139 @@
+// Copyright 2025 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gormadapter
+
+import (
+	"database/sql"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/plugin/dbresolver"
+)
+
+// writeTracker remembers the last write of an adapter for read-your-writes.
+type writeTracker struct {
+	window    time.Duration
+	lastWrite atomic.Int64
+}
+
+// WithReplicas sends LoadPolicy, LoadFilteredPolicy and the other reads of the adapter to the given read replicas,
+// while all writes go to the primary. The replicas are registered through gorm.io/plugin/dbresolver, once the table
+// has been migrated on the primary, on a gorm instance of the adapter's own that shares the connection pool of the
+// given one, so that the other queries on the given instance are not routed to the replicas.
+func WithReplicas(replicas ...gorm.Dialector) Option {
+	return func(a *Adapter) error {
+		if len(replicas) == 0 {
+			return errors.New("no replica given")
+		}
+		a.replicas = replicas
+		return nil
+	}
+}
+
+// WithReadYourWrites pins reads to the primary for window after every write through the adapter,
+// so that a reload right after AddPolicy does not see a lagging replica.
+// It works with WithReplicas as well as with a dbresolver registered by the caller.
+func WithReadYourWrites(window time.Duration) Option {
+	return func(a *Adapter) error {
+		if window <= 0 {
+			return errors.New("read-your-writes window must be positive")
+		}
+		a.writeTracker = &writeTracker{window: window}
+		return nil
+	}
+}
+
+// registerReplicas registers the configured read replicas on a gorm instance owned by the adapter.
+func (a *Adapter) registerReplicas() error {
+	if len(a.replicas) == 0 {
+		return nil
+	}
+	db, err := a.ownDB()
+	if err != nil {
+		return err
+	}
+	if err := db.Use(dbresolver.Register(dbresolver.Config{Replicas: a.replicas})); err != nil {
+		return err
+	}
+	a.db = db
+	return nil
+}
+
+// ownDB opens a gorm instance with the configuration and the connection pool of the adapter's one,
+// but its own callbacks and plugins.
+func (a *Adapter) ownDB() (*gorm.DB, error) {
+	sqlDB, err := a.db.DB()
+	if err != nil {
+		return nil, err
+	}
+	config := *a.db.Config
+	config.Plugins = map[string]gorm.Plugin{}
+	config.ClauseBuilders = make(map[string]clause.ClauseBuilder, len(a.db.ClauseBuilders))
+	for name, builder := range a.db.ClauseBuilders {
+		config.ClauseBuilders[name] = builder
+	}
+	// The pool is already wrapped for prepared statements if the adapter's instance uses them.
+	config.PrepareStmt = false
+	return gorm.Open(pooledDialector{Dialector: a.db.Dialector, pool: a.db.ConnPool, sqlDB: sqlDB}, &config)
+}
+
+// pooledDialector initializes a gorm instance like its dialector but on an existing connection pool.
+type pooledDialector struct {
+	gorm.Dialector
+	pool  gorm.ConnPool
+	sqlDB *sql.DB
+}
+
+func (d pooledDialector) Initialize(db *gorm.DB) error {
+	if err := d.Dialector.Initialize(db); err != nil {
+		return err
+	}
+	// Close the pool the dialector may have opened unless it is the shared one.
+	if opened, ok := db.ConnPool.(*sql.DB); ok && opened != d.sqlDB {
+		_ = opened.Close()
+	}
+	db.ConnPool = d.pool
+	return nil
+}
+
+// markWrite starts the read-your-writes window.
+func (a *Adapter) markWrite() {
+	if a.writeTracker != nil {
+		a.writeTracker.lastWrite.Store(time.Now().UnixNano())
+	}
+}
+
+// primary routes a read that decides what a write does to the primary, like the write itself,
+// so that a lagging replica cannot make the write miss rows.
+func primary(db *gorm.DB) *gorm.DB {
+	return db.Clauses(dbresolver.Write)
+}
+
+// readScope routes a read to the primary while the read-your-writes window is open.
+func (a *Adapter) readScope() func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if a.writeTracker == nil {
+			return db
+		}
+		lastWrite := a.writeTracker.lastWrite.Load()
+		if lastWrite == 0 || time.Since(time.Unix(0, lastWrite)) >= a.writeTracker.window {
+			return db
+		}
+		return db.Clauses(dbresolver.Write)
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2025 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gormadapter
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/anzimu/casbin/v2"
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openReplicatedDBs opens a primary and a replica that never catches up, migrated with opts,
+// which makes it visible where a read was served from.
+func openReplicatedDBs(t *testing.T, opts ...Option) (*gorm.DB, gorm.Dialector) {
+	dir := t.TempDir()
+	replica := sqlite.Open(filepath.Join(dir, "replica.db"))
+	replicaDB, err := gorm.Open(replica, &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	assert.NoError(t, err)
+	_, err = NewAdapterByDBWithOptions(replicaDB, "", "", append(opts, WithAutoMigrate())...)
+	assert.NoError(t, err)
+	assert.NoError(t, replicaDB.Create(&CasbinRule{Ptype: "p", V0: "replica", V1: "data1", V2: "read"}).Error)
+
+	primaryDB, err := gorm.Open(sqlite.Open(filepath.Join(dir, "primary.db")), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	assert.NoError(t, err)
+	return primaryDB, replica
+}
+
+func TestReplicasServeReads(t *testing.T) {
+	primaryDB, replica := openReplicatedDBs(t)
+	a, err := NewAdapterByDBWithOptions(primaryDB, "", "", WithReplicas(replica), WithAutoMigrate())
+	assert.NoError(t, err)
+
+	assert.NoError(t, a.AddPolicy("p", "p", []string{"alice", "data1", "read"}))
+	e, _ := casbin.NewEnforcer("examples/rbac_model.conf", a)
+	testGetPolicy(t, e, [][]string{{"replica", "data1", "read"}})
+	assert.NoError(t, e.LoadFilteredPolicy(Filter{V1: []string{"data1"}}))
+	testGetPolicy(t, e, [][]string{{"replica", "data1", "read"}})
+
+	// The given instance is not routed to the replicas.
+	var count int64
+	assert.NoError(t, primaryDB.Table("casbin_rule").Where("v0 = ?", "alice").Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+
+	// A second adapter can use replicas on the same instance.
+	b, err := NewAdapterByDBWithOptions(primaryDB, "", "", WithReplicas(replica))
+	assert.NoError(t, err)
+	e, _ = casbin.NewEnforcer("examples/rbac_model.conf", b)
+	testGetPolicy(t, e, [][]string{{"replica", "data1", "read"}})
+}
+
+func TestReadYourWrites(t *testing.T) {
+	primaryDB, replica := openReplicatedDBs(t)
+	a, err := NewAdapterByDBWithOptions(primaryDB, "", "", WithReplicas(replica), WithReadYourWrites(200*time.Millisecond), WithAutoMigrate())
+	assert.NoError(t, err)
+
+	e, _ := casbin.NewEnforcer("examples/rbac_model.conf", a)
+	testGetPolicy(t, e, [][]string{{"replica", "data1", "read"}})
+
+	assert.NoError(t, a.AddPolicy("p", "p", []string{"alice", "data1", "read"}))
+	assert.NoError(t, e.LoadPolicy())
+	testGetPolicy(t, e, [][]string{{"alice", "data1", "read"}})
+
+	time.Sleep(250 * time.Millisecond)
+	assert.NoError(t, e.LoadPolicy())
+	testGetPolicy(t, e, [][]string{{"replica", "data1", "read"}})
+}
+
+func TestReplicasMutationReads(t *testing.T) {
+	primaryDB, replica := openReplicatedDBs(t, WithSoftDelete(), WithValidity())
+	a, err := NewAdapterByDBWithOptions(primaryDB, "", "", WithReplicas(replica), WithSoftDelete(), WithValidity(), WithAutoMigrate())
+	assert.NoError(t, err)
+
+	// The soft-deleted copy is looked up on the primary, so the rule can be added again.
+	rule := []string{"alice", "data1", "read"}
+	assert.NoError(t, a.AddPolicy("p", "p", rule))
+	assert.NoError(t, a.RemovePolicy("p", "p", rule))
+	assert.NoError(t, a.AddPolicy("p", "p", rule))
+
+	// SavePolicy keeps the rule that is not valid yet, which only the primary knows.
+	now := time.Now()
+	assert.NoError(t, a.AddPolicyBetween("p", "p", []string{"bob", "data2", "write"}, now.Add(time.Hour), now.Add(2*time.Hour)))
+	e, err := casbin.NewEnforcer("examples/rbac_model.conf", a)
+	assert.NoError(t, err)
+	assert.NoError(t, e.SavePolicy())
+
+	var subjects []string
+	assert.NoError(t, primaryDB.Table("casbin_rule").Order("v0").Pluck("v0", &subjects).Error)
+	assert.Equal(t, []string{"bob", "replica"}, subjects)
+}
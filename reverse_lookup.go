@@ -0,0 +1,229 @@
+// Copyright 2025 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gormadapter
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/anzimu/casbin/v2/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// roleLinkPtype is the ptype of the role links followed by the reverse lookups.
+const roleLinkPtype = "g"
+
+// reverseQuery builds a raw reverse lookup query over the rule table.
+type reverseQuery struct {
+	adapter *Adapter
+	db      *gorm.DB
+	sql     strings.Builder
+	vars    []interface{}
+	err     error
+}
+
+func (q *reverseQuery) add(sql string, vars ...interface{}) {
+	q.sql.WriteString(sql)
+	q.vars = append(q.vars, vars...)
+}
+
+// from adds the rule table aliased as alias.
+func (q *reverseQuery) from(alias string) {
+	q.add(" FROM ?", clause.Table{Name: q.adapter.getFullTableName(), Alias: alias})
+}
+
+// eq adds a condition on a column of the rule table aliased as alias.
+func (q *reverseQuery) eq(alias, column string, value interface{}) {
+	q.add(" AND ? = ?", clause.Column{Table: alias, Name: column}, value)
+}
+
+// visible restricts the rule table aliased as alias to the rules of the current tenant
+// that are valid now and not soft-deleted.
+func (q *reverseQuery) visible(alias string) {
+	cond, err := q.adapter.tenantCondition(q.db, alias)
+	if err != nil {
+		q.err = err
+		return
+	}
+	if cond != nil {
+		q.add(" AND ?", cond)
+	}
+	if q.adapter.softDelete {
+		q.add(" AND ?", clause.Eq{Column: clause.Column{Table: alias, Name: deletedAtColumn}, Value: nil})
+	}
+	if q.adapter.validity {
+		q.add(" AND ?", validityCondition(alias, time.Now()))
+	}
+}
+
+func (q *reverseQuery) scan() ([]string, error) {
+	if q.err != nil {
+		return nil, q.err
+	}
+	names := []string{}
+	err := q.db.Scopes(q.adapter.readScope()).Raw(q.sql.String(), q.vars...).Scan(&names).Error
+	return names, err
+}
+
+// lookupColumns are the columns read by a reverse lookup.
+type lookupColumns struct {
+	sub, dom, obj, act string
+	// user, role and roleDom are the columns of the "g" role links.
+	user, role, roleDom string
+}
+
+// columnsFor resolves the columns of the sub, dom, obj and act fields of ptype and of the
+// user, role and domain fields of the role links from the model.
+func columnsFor(m model.Model, ptype string, withDomain bool) (*lookupColumns, error) {
+	fields, err := fieldIndexes(m, ptype)
+	if err != nil {
+		return nil, err
+	}
+	names := []string{"sub", "obj", "act"}
+	if withDomain {
+		names = append(names, "dom")
+	}
+	columns := make(map[string]string, len(names))
+	for _, name := range names {
+		index, ok := fields[name]
+		if !ok {
+			return nil, fmt.Errorf("ptype %q has no %s field", ptype, name)
+		}
+		columns[name] = fmt.Sprintf("v%d", index)
+	}
+
+	// Without role definition there are no role links to follow, the default columns find none.
+	roles := roleFieldNames
+	if _, ok := m["g"][roleLinkPtype]; ok {
+		if roles, err = fieldIndexes(m, roleLinkPtype); err != nil {
+			return nil, err
+		}
+	}
+	if _, ok := roles["domain"]; withDomain && !ok {
+		return nil, fmt.Errorf("ptype %q has no domain field", roleLinkPtype)
+	}
+	return &lookupColumns{
+		sub:     columns["sub"],
+		dom:     columns["dom"],
+		obj:     columns["obj"],
+		act:     columns["act"],
+		user:    fmt.Sprintf("v%d", roles["user"]),
+		role:    fmt.Sprintf("v%d", roles["role"]),
+		roleDom: fmt.Sprintf("v%d", roles["domain"]),
+	}, nil
+}
+
+func lookupDomain(domain []string) (string, bool, error) {
+	switch len(domain) {
+	case 0:
+		return "", false, nil
+	case 1:
+		return domain[0], true, nil
+	default:
+		return "", false, errors.New("at most one domain is allowed")
+	}
+}
+
+// SubjectsFor returns the subjects that may perform act on obj under the policy type ptype,
+// optionally within a domain. Besides the subjects of matching rules, it returns every user
+// and role that inherits them through the "g" role links.
+// The columns of the sub, dom, obj and act fields are taken from the policy definition of m.
+func (a *Adapter) SubjectsFor(m model.Model, ptype, obj, act string, domain ...string) ([]string, error) {
+	return a.subjectsFor(a.db, m, ptype, obj, act, domain...)
+}
+
+func (a *Adapter) subjectsFor(db *gorm.DB, m model.Model, ptype, obj, act string, domain ...string) ([]string, error) {
+	dom, withDomain, err := lookupDomain(domain)
+	if err != nil {
+		return nil, err
+	}
+	c, err := columnsFor(m, ptype, withDomain)
+	if err != nil {
+		return nil, err
+	}
+
+	q := &reverseQuery{adapter: a, db: db}
+	q.add("WITH RECURSIVE subjects(name) AS (SELECT ?", clause.Column{Table: "p", Name: c.sub})
+	q.from("p")
+	q.add(" WHERE ? = ?", clause.Column{Table: "p", Name: "ptype"}, ptype)
+	q.eq("p", c.obj, obj)
+	q.eq("p", c.act, act)
+	if withDomain {
+		q.eq("p", c.dom, dom)
+	}
+	q.visible("p")
+	q.add(" UNION SELECT ?", clause.Column{Table: "g", Name: c.user})
+	q.from("g")
+	q.add(" JOIN subjects s ON ? = s.name WHERE ? = ?",
+		clause.Column{Table: "g", Name: c.role}, clause.Column{Table: "g", Name: "ptype"}, roleLinkPtype)
+	if withDomain {
+		q.eq("g", c.roleDom, dom)
+	}
+	q.visible("g")
+	q.add(") SELECT name FROM subjects ORDER BY name")
+	return q.scan()
+}
+
+// ObjectsFor returns the objects on which sub may perform act under the policy type ptype,
+// optionally within a domain. Rules granted to the roles that sub inherits through the "g"
+// role links are included.
+// The columns of the sub, dom, obj and act fields are taken from the policy definition of m.
+func (a *Adapter) ObjectsFor(m model.Model, ptype, sub, act string, domain ...string) ([]string, error) {
+	return a.objectsFor(a.db, m, ptype, sub, act, domain...)
+}
+
+func (a *Adapter) objectsFor(db *gorm.DB, m model.Model, ptype, sub, act string, domain ...string) ([]string, error) {
+	dom, withDomain, err := lookupDomain(domain)
+	if err != nil {
+		return nil, err
+	}
+	c, err := columnsFor(m, ptype, withDomain)
+	if err != nil {
+		return nil, err
+	}
+
+	q := &reverseQuery{adapter: a, db: db}
+	q.add("WITH RECURSIVE roles(name) AS (SELECT ?", clause.Column{Table: "g", Name: c.role})
+	q.from("g")
+	q.add(" WHERE ? = ?", clause.Column{Table: "g", Name: "ptype"}, roleLinkPtype)
+	q.eq("g", c.user, sub)
+	if withDomain {
+		q.eq("g", c.roleDom, dom)
+	}
+	q.visible("g")
+	q.add(" UNION SELECT ?", clause.Column{Table: "l", Name: c.role})
+	q.from("l")
+	q.add(" JOIN roles r ON ? = r.name WHERE ? = ?",
+		clause.Column{Table: "l", Name: c.user}, clause.Column{Table: "l", Name: "ptype"}, roleLinkPtype)
+	if withDomain {
+		q.eq("l", c.roleDom, dom)
+	}
+	q.visible("l")
+	q.add(") SELECT DISTINCT ?", clause.Column{Table: "p", Name: c.obj})
+	q.from("p")
+	q.add(" WHERE ? = ?", clause.Column{Table: "p", Name: "ptype"}, ptype)
+	q.eq("p", c.act, act)
+	if withDomain {
+		q.eq("p", c.dom, dom)
+	}
+	q.visible("p")
+	q.add(" AND (? = ? OR ? IN (SELECT name FROM roles)) ORDER BY ?",
+		clause.Column{Table: "p", Name: c.sub}, sub, clause.Column{Table: "p", Name: c.sub},
+		clause.Column{Table: "p", Name: c.obj})
+	return q.scan()
+}
@@ -0,0 +1,143 @@
+// Copyright 2025 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gormadapter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/anzimu/casbin/v2/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func loadModel(t *testing.T, path string) model.Model {
+	m, err := model.NewModelFromFile(path)
+	assert.NoError(t, err)
+	return m
+}
+
+func TestReverseLookup(t *testing.T) {
+	db := openSqliteTestDB(t)
+	a, err := NewAdapterByDBWithOptions(db, "", "", WithAutoMigrate())
+	assert.NoError(t, err)
+	initPolicy(t, a)
+	m := loadModel(t, "examples/rbac_model.conf")
+	// carol inherits data2_admin through alice.
+	assert.NoError(t, a.AddPolicy("g", "g", []string{"carol", "alice"}))
+
+	subjects, err := a.SubjectsFor(m, "p", "data2", "write")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"alice", "bob", "carol", "data2_admin"}, subjects)
+
+	subjects, err = a.SubjectsFor(m, "p", "data1", "write")
+	assert.NoError(t, err)
+	assert.Empty(t, subjects)
+
+	objects, err := a.ObjectsFor(m, "p", "carol", "read")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"data1", "data2"}, objects)
+
+	objects, err = a.ObjectsFor(m, "p", "bob", "read")
+	assert.NoError(t, err)
+	assert.Empty(t, objects)
+
+	_, err = a.ObjectsFor(m, "p", "bob", "read", "domain1", "domain2")
+	assert.Error(t, err)
+}
+
+func TestReverseLookupWithDomain(t *testing.T) {
+	a := initDomainPolicy(t, 3)
+	m := loadModel(t, "examples/rbac_with_domains_model.conf")
+
+	subjects, err := a.SubjectsFor(m, "p", "data1", "read", "domain1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"admin", "user1"}, subjects)
+
+	objects, err := a.ObjectsFor(m, "p", "user1", "write", "domain1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"data1"}, objects)
+
+	objects, err = a.ObjectsFor(m, "p", "user1", "write", "domain2")
+	assert.NoError(t, err)
+	assert.Empty(t, objects)
+}
+
+func TestReverseLookupWithTenant(t *testing.T) {
+	type tenantKey struct{}
+	type dbKey struct{}
+	db := openSqliteTestDB(t)
+	ca, err := NewContextAdapterByDBWithOptions(dbKey{}, db, "", "",
+		WithTenant("tenant", TenantFromContext(tenantKey{})), WithAutoMigrate())
+	assert.NoError(t, err)
+	m := loadModel(t, "examples/rbac_model.conf")
+
+	ctx1 := context.WithValue(context.WithValue(context.Background(), dbKey{}, db), tenantKey{}, "t1")
+	ctx2 := context.WithValue(context.WithValue(context.Background(), dbKey{}, db), tenantKey{}, "t2")
+	assert.NoError(t, ca.AddPolicyCtx(ctx1, "p", "p", []string{"admin", "data1", "read"}))
+	assert.NoError(t, ca.AddPolicyCtx(ctx1, "g", "g", []string{"alice", "admin"}))
+	assert.NoError(t, ca.AddPolicyCtx(ctx2, "g", "g", []string{"bob", "admin"}))
+
+	subjects, err := ca.SubjectsForCtx(ctx1, m, "p", "data1", "read")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"admin", "alice"}, subjects)
+
+	objects, err := ca.ObjectsForCtx(ctx2, m, "p", "bob", "read")
+	assert.NoError(t, err)
+	assert.Empty(t, objects)
+}
+
+func TestReverseLookupFieldOrder(t *testing.T) {
+	db := openSqliteTestDB(t)
+	a, err := NewAdapterByDBWithOptions(db, "", "", WithAutoMigrate())
+	assert.NoError(t, err)
+	m, err := model.NewModelFromString(`
+[request_definition]
+r = sub, obj, act
+
+[policy_definition]
+p = act, obj, sub
+
+[role_definition]
+g = _, _
+
+[policy_effect]
+e = some(where (p.eft == allow))
+
+[matchers]
+m = g(r.sub, p.sub) && r.obj == p.obj && r.act == p.act
+`)
+	assert.NoError(t, err)
+	assert.NoError(t, a.AddPolicy("p", "p", []string{"read", "data1", "admin"}))
+	assert.NoError(t, a.AddPolicy("p", "p", []string{"write", "data2", "bob"}))
+	assert.NoError(t, a.AddPolicy("g", "g", []string{"alice", "admin"}))
+
+	subjects, err := a.SubjectsFor(m, "p", "data1", "read")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"admin", "alice"}, subjects)
+
+	objects, err := a.ObjectsFor(m, "p", "alice", "read")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"data1"}, objects)
+
+	objects, err = a.ObjectsFor(m, "p", "bob", "write")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"data2"}, objects)
+
+	// The model has no p2 and its p has no domain.
+	_, err = a.SubjectsFor(m, "p2", "data1", "read")
+	assert.Error(t, err)
+	_, err = a.ObjectsFor(m, "p", "alice", "read", "domain1")
+	assert.Error(t, err)
+}
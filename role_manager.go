@@ -0,0 +1,305 @@
+// Copyright 2025 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gormadapter
+
+import (
+	"container/list"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/anzimu/casbin/v2/log"
+	"github.com/anzimu/casbin/v2/rbac"
+	"gorm.io/gorm/clause"
+)
+
+const defaultRoleMaxDepth = 10
+
+// RoleManagerConfig configures a RoleManager.
+type RoleManagerConfig struct {
+	// Ptype is the ptype of the role links, "g" by default.
+	Ptype string
+	// MaxDepth is the maximum length of an inheritance chain, 10 by default.
+	MaxDepth int
+	// CacheSize is the number of query results kept in memory, 0 disables the cache.
+	CacheSize int
+}
+
+// RoleManager is a rbac.RoleManager that resolves the role links with SQL against the
+// rule table of an adapter instead of holding them in memory. The domain of a role link is in v2.
+//
+// The rule table is the source of truth: AddLink, DeleteLink and Clear only drop the cached results,
+// the links themselves are written through the adapter. Pattern matching functions are not supported.
+//
+// Example:
+//
+//	rm := NewRoleManager(a, RoleManagerConfig{CacheSize: 1000})
+//	e.SetRoleManager(rm)
+//	err := e.LoadPolicy() // or load only the p rules and call e.BuildRoleLinks()
+type RoleManager struct {
+	adapter  *Adapter
+	ptype    string
+	maxDepth int
+	cache    *resultCache
+	logger   log.Logger
+}
+
+var _ rbac.RoleManager = (*RoleManager)(nil)
+
+// NewRoleManager returns a RoleManager over the role links of adapter a.
+func NewRoleManager(a *Adapter, config RoleManagerConfig) *RoleManager {
+	if config.Ptype == "" {
+		config.Ptype = "g"
+	}
+	if config.MaxDepth <= 0 {
+		config.MaxDepth = defaultRoleMaxDepth
+	}
+	rm := &RoleManager{
+		adapter:  a,
+		ptype:    config.Ptype,
+		maxDepth: config.MaxDepth,
+		logger:   &log.DefaultLogger{},
+	}
+	if config.CacheSize > 0 {
+		rm.cache = newResultCache(config.CacheSize)
+	}
+	return rm
+}
+
+// Clear drops the cached results.
+func (rm *RoleManager) Clear() error {
+	if rm.cache != nil {
+		rm.cache.clear()
+	}
+	return nil
+}
+
+// AddLink drops the cached results, the link itself is stored by the adapter.
+func (rm *RoleManager) AddLink(name1 string, name2 string, domain ...string) error {
+	return rm.Clear()
+}
+
+// Deprecated: BuildRelationship is no longer required
+func (rm *RoleManager) BuildRelationship(name1 string, name2 string, domain ...string) error {
+	return nil
+}
+
+// DeleteLink drops the cached results, the link itself is removed by the adapter.
+func (rm *RoleManager) DeleteLink(name1 string, name2 string, domain ...string) error {
+	return rm.Clear()
+}
+
+// HasLink determines whether role: name1 inherits role: name2.
+func (rm *RoleManager) HasLink(name1 string, name2 string, domain ...string) (bool, error) {
+	if name1 == name2 {
+		return true, nil
+	}
+	roles, err := rm.GetImplicitRoles(name1, domain...)
+	if err != nil {
+		return false, err
+	}
+	for _, role := range roles {
+		if role == name2 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// GetRoles gets the roles that a user directly inherits.
+func (rm *RoleManager) GetRoles(name string, domain ...string) ([]string, error) {
+	return rm.cached("roles", name, domain, func(dom string, withDomain bool) *reverseQuery {
+		q := rm.query("SELECT ?", clause.Column{Table: "g", Name: "v1"})
+		rm.links(q, "g", dom, withDomain)
+		q.eq("g", "v0", name)
+		q.add(" ORDER BY ?", clause.Column{Table: "g", Name: "v1"})
+		return q
+	})
+}
+
+// GetUsers gets the users that directly inherit a role.
+func (rm *RoleManager) GetUsers(name string, domain ...string) ([]string, error) {
+	return rm.cached("users", name, domain, func(dom string, withDomain bool) *reverseQuery {
+		q := rm.query("SELECT ?", clause.Column{Table: "g", Name: "v0"})
+		rm.links(q, "g", dom, withDomain)
+		q.eq("g", "v1", name)
+		q.add(" ORDER BY ?", clause.Column{Table: "g", Name: "v0"})
+		return q
+	})
+}
+
+// GetImplicitRoles gets the roles that a user inherits directly or indirectly,
+// following at most MaxDepth links.
+func (rm *RoleManager) GetImplicitRoles(name string, domain ...string) ([]string, error) {
+	return rm.cached("implicit", name, domain, func(dom string, withDomain bool) *reverseQuery {
+		q := rm.query("WITH RECURSIVE roles(name, depth) AS (SELECT ?, 1", clause.Column{Table: "g", Name: "v1"})
+		rm.links(q, "g", dom, withDomain)
+		q.eq("g", "v0", name)
+		q.add(" UNION SELECT ?, r.depth + 1", clause.Column{Table: "l", Name: "v1"})
+		q.from("l")
+		q.add(" JOIN roles r ON ? = r.name", clause.Column{Table: "l", Name: "v0"})
+		rm.where(q, "l", dom, withDomain)
+		q.add(" AND r.depth < ?) SELECT DISTINCT name FROM roles ORDER BY name", rm.maxDepth)
+		return q
+	})
+}
+
+// GetDomains gets the domains in which a user has roles.
+func (rm *RoleManager) GetDomains(name string) ([]string, error) {
+	q := rm.query("SELECT DISTINCT ?", clause.Column{Table: "g", Name: "v2"})
+	rm.links(q, "g", "", false)
+	q.eq("g", "v0", name)
+	q.add(" ORDER BY ?", clause.Column{Table: "g", Name: "v2"})
+	return q.scan()
+}
+
+// GetAllDomains gets all domains of the role links.
+func (rm *RoleManager) GetAllDomains() ([]string, error) {
+	q := rm.query("SELECT DISTINCT ?", clause.Column{Table: "g", Name: "v2"})
+	rm.links(q, "g", "", false)
+	q.add(" AND ? <> '' ORDER BY ?", clause.Column{Table: "g", Name: "v2"}, clause.Column{Table: "g", Name: "v2"})
+	return q.scan()
+}
+
+// PrintRoles prints all the role links to log.
+func (rm *RoleManager) PrintRoles() error {
+	if !rm.logger.IsEnabled() {
+		return nil
+	}
+	var lines []CasbinRule
+	if err := rm.adapter.db.Scopes(rm.adapter.casbinRuleTable(), rm.adapter.readScope(), rm.adapter.validNow()).
+		Where("ptype = ?", rm.ptype).Order("id").Find(&lines).Error; err != nil {
+		return err
+	}
+	roles := make([]string, 0, len(lines))
+	for _, line := range lines {
+		roles = append(roles, fmt.Sprintf("%s < %s", line.V0, line.V1))
+	}
+	rm.logger.LogRole(roles)
+	return nil
+}
+
+// SetLogger sets role manager's logger.
+func (rm *RoleManager) SetLogger(logger log.Logger) {
+	rm.logger = logger
+}
+
+// Match matches the domain with the pattern, only equal strings match.
+func (rm *RoleManager) Match(str string, pattern string) bool {
+	return str == pattern
+}
+
+// AddMatchingFunc is a no-op, pattern matching is not supported.
+func (rm *RoleManager) AddMatchingFunc(name string, fn rbac.MatchingFunc) {}
+
+// AddDomainMatchingFunc is a no-op, pattern matching is not supported.
+func (rm *RoleManager) AddDomainMatchingFunc(name string, fn rbac.MatchingFunc) {}
+
+func (rm *RoleManager) query(sql string, vars ...interface{}) *reverseQuery {
+	q := &reverseQuery{adapter: rm.adapter, db: rm.adapter.db}
+	q.add(sql, vars...)
+	return q
+}
+
+// links adds the role link rows aliased as alias.
+func (rm *RoleManager) links(q *reverseQuery, alias string, dom string, withDomain bool) {
+	q.from(alias)
+	rm.where(q, alias, dom, withDomain)
+}
+
+func (rm *RoleManager) where(q *reverseQuery, alias string, dom string, withDomain bool) {
+	q.add(" WHERE ? = ?", clause.Column{Table: alias, Name: "ptype"}, rm.ptype)
+	if withDomain {
+		q.eq(alias, "v2", dom)
+	}
+	q.visible(alias)
+}
+
+// cached runs the query built by build, or returns its cached result.
+func (rm *RoleManager) cached(op, name string, domain []string, build func(dom string, withDomain bool) *reverseQuery) ([]string, error) {
+	dom, withDomain, err := lookupDomain(domain)
+	if err != nil {
+		return nil, err
+	}
+	key := strings.Join(append([]string{op, name}, domain...), "\x00")
+	if rm.cache != nil {
+		if names, ok := rm.cache.get(key); ok {
+			return names, nil
+		}
+	}
+	names, err := build(dom, withDomain).scan()
+	if err != nil {
+		return nil, err
+	}
+	if rm.cache != nil {
+		rm.cache.put(key, names)
+	}
+	return names, nil
+}
+
+type cacheEntry struct {
+	key   string
+	names []string
+}
+
+// resultCache is a LRU cache of query results.
+// It keeps its own copies of the results, so that callers may modify the slices they get.
+type resultCache struct {
+	mu       sync.Mutex
+	capacity int
+	lru      *list.List
+	entries  map[string]*list.Element
+}
+
+func newResultCache(capacity int) *resultCache {
+	c := &resultCache{capacity: capacity}
+	c.clear()
+	return c
+}
+
+func (c *resultCache) get(key string) ([]string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.lru.MoveToFront(elem)
+	return append([]string{}, elem.Value.(*cacheEntry).names...), true
+}
+
+func (c *resultCache) put(key string, names []string) {
+	names = append([]string{}, names...)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[key]; ok {
+		elem.Value.(*cacheEntry).names = names
+		c.lru.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(&cacheEntry{key: key, names: names})
+	if c.lru.Len() > c.capacity {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+func (c *resultCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lru = list.New()
+	c.entries = make(map[string]*list.Element)
+}
@@ -0,0 +1,165 @@
+// Copyright 2025 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gormadapter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/anzimu/casbin/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoleManager(t *testing.T) {
+	db := openSqliteTestDB(t)
+	a, err := NewAdapterByDBWithOptions(db, "", "", WithAutoMigrate())
+	assert.NoError(t, err)
+	initPolicy(t, a)
+	assert.NoError(t, a.AddPolicies("g", "g", [][]string{{"carol", "alice"}, {"dave", "carol"}}))
+
+	rm := NewRoleManager(a, RoleManagerConfig{})
+	roles, err := rm.GetRoles("carol")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"alice"}, roles)
+	users, err := rm.GetUsers("alice")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"carol"}, users)
+	roles, err = rm.GetImplicitRoles("dave")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"alice", "carol", "data2_admin"}, roles)
+	ok, err := rm.HasLink("dave", "data2_admin")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = rm.HasLink("data2_admin", "dave")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// dave reaches data2_admin in three links.
+	ok, err = NewRoleManager(a, RoleManagerConfig{MaxDepth: 2}).HasLink("dave", "data2_admin")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// The enforcer only needs the p rules in memory.
+	e, err := casbin.NewEnforcer("examples/rbac_model.conf")
+	assert.NoError(t, err)
+	e.SetRoleManager(rm)
+	assert.NoError(t, a.LoadFilteredPolicy(e.GetModel(), Filter{Ptype: []string{"p"}}))
+	assert.NoError(t, e.BuildRoleLinks())
+	assert.Empty(t, e.GetGroupingPolicy())
+	ok, err = e.Enforce("dave", "data2", "write")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = e.Enforce("bob", "data1", "read")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestRoleManagerWithDomain(t *testing.T) {
+	a := initDomainPolicy(t, 3)
+	assert.NoError(t, a.AddPolicy("g", "g", []string{"user1", "admin", "domain2"}))
+
+	rm := NewRoleManager(a, RoleManagerConfig{})
+	ok, err := rm.HasLink("user1", "admin", "domain2")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = rm.HasLink("user0", "admin", "domain2")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	domains, err := rm.GetDomains("user1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"domain1", "domain2"}, domains)
+	domains, err = rm.GetAllDomains()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"domain0", "domain1", "domain2"}, domains)
+
+	e, err := casbin.NewEnforcer("examples/rbac_with_domains_model.conf")
+	assert.NoError(t, err)
+	e.SetRoleManager(rm)
+	assert.NoError(t, a.LoadFilteredPolicy(e.GetModel(), Filter{Ptype: []string{"p"}}))
+	assert.NoError(t, e.BuildRoleLinks())
+	ok, err = e.Enforce("user1", "domain2", "data2", "read")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = e.Enforce("user0", "domain2", "data2", "read")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestRoleManagerCache(t *testing.T) {
+	db := openSqliteTestDB(t)
+	a, err := NewAdapterByDBWithOptions(db, "", "", WithAutoMigrate())
+	assert.NoError(t, err)
+	initPolicy(t, a)
+
+	rm := NewRoleManager(a, RoleManagerConfig{CacheSize: 1})
+	roles, err := rm.GetRoles("alice")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"data2_admin"}, roles)
+
+	// The cached result is served until the links change through the role manager.
+	assert.NoError(t, a.RemovePolicy("g", "g", []string{"alice", "data2_admin"}))
+	roles, err = rm.GetRoles("alice")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"data2_admin"}, roles)
+	assert.NoError(t, rm.DeleteLink("alice", "data2_admin"))
+	roles, err = rm.GetRoles("alice")
+	assert.NoError(t, err)
+	assert.Empty(t, roles)
+
+	// The least recently used result is evicted.
+	assert.NoError(t, a.AddPolicy("g", "g", []string{"alice", "data2_admin"}))
+	_, err = rm.GetUsers("data2_admin")
+	assert.NoError(t, err)
+	roles, err = rm.GetRoles("alice")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"data2_admin"}, roles)
+
+	// Modifying a result leaves the cached one intact.
+	roles[0] = "admin"
+	roles, err = rm.GetRoles("alice")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"data2_admin"}, roles)
+	roles, err = rm.GetImplicitRoles("alice")
+	assert.NoError(t, err)
+	roles[0] = "admin"
+	ok, err := rm.HasLink("alice", "data2_admin")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestRoleManagerValidity(t *testing.T) {
+	db := openSqliteTestDB(t)
+	a, err := NewAdapterByDBWithOptions(db, "", "", WithValidity(), WithAutoMigrate())
+	assert.NoError(t, err)
+	assert.NoError(t, a.AddPolicyUntil("g", "g", []string{"alice", "admin"}, time.Now().Add(-time.Minute)))
+	assert.NoError(t, a.AddPolicyFor("g", "g", []string{"bob", "admin"}, time.Hour))
+
+	rm := NewRoleManager(a, RoleManagerConfig{})
+	ok, err := rm.HasLink("alice", "admin")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	ok, err = rm.HasLink("bob", "admin")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	users, err := rm.GetUsers("admin")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"bob"}, users)
+
+	count, err := a.CountRules(context.Background(), Query{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
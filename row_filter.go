@@ -0,0 +1,202 @@
+// Copyright 2025 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gormadapter
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+
+	casbinerrors "github.com/anzimu/casbin/v2/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+const rowFilterName = "casbin:row_filter"
+
+// ErrSubjectNotFound is returned when the subject of a statement cannot be resolved.
+var ErrSubjectNotFound = errors.New("[casbin] gorm adapter error: subject not found")
+
+// SubjectResolver returns the subject of the statements run with ctx.
+type SubjectResolver func(ctx context.Context) (string, error)
+
+// SubjectFromContext resolves the subject from the string value stored in the context under key.
+func SubjectFromContext(key interface{}) SubjectResolver {
+	return func(ctx context.Context) (string, error) {
+		if subject, ok := ctx.Value(key).(string); ok && subject != "" {
+			return subject, nil
+		}
+		return "", ErrSubjectNotFound
+	}
+}
+
+type skipAuthorizationKey struct{}
+
+// SkipAuthorization returns a context whose statements are not checked by the casbin gorm plugins.
+func SkipAuthorization(ctx context.Context) context.Context {
+	return context.WithValue(ctx, skipAuthorizationKey{}, true)
+}
+
+func authorizationSkipped(ctx context.Context) bool {
+	skip, _ := ctx.Value(skipAuthorizationKey{}).(bool)
+	return skip
+}
+
+// ObjectConditionEnforcer is the part of the casbin enforcer the RowFilter needs, e.g. *casbin.Enforcer.
+type ObjectConditionEnforcer interface {
+	GetAllowedObjectConditions(user string, action string, prefix string) ([]string, error)
+}
+
+// RowFiltered is implemented by the models whose queries are filtered by a RowFilter.
+// RowFilterAction returns the action to check, or "" for the action of the RowFilter.
+type RowFiltered interface {
+	RowFilterAction() string
+}
+
+// RowFilterConfig configures a RowFilter.
+type RowFilterConfig struct {
+	// Enforcer returns the object conditions of the subjects.
+	Enforcer ObjectConditionEnforcer
+	// Subject resolves the subject from the statement context.
+	Subject SubjectResolver
+	// Action is the action to check, "read" by default.
+	Action string
+	// Prefix is the prefix of the object conditions, "r.obj." by default.
+	Prefix string
+	// Models are the models to filter besides those implementing RowFiltered.
+	Models []interface{}
+	// Fields maps fields to columns besides the fields of the models, see ConditionOptions.
+	Fields map[string]string
+}
+
+// RowFilter is a gorm plugin that restricts the queries of the opted-in models to the rows the subject
+// of the statement may access: the object conditions of the subject, as returned by
+// GetAllowedObjectConditions, are translated as by SafeConditionsToGormQuery and combined with OR into
+// the WHERE clause. A subject without conditions gets no rows. Raw SQL is not filtered.
+//
+// Example:
+//
+//	f, _ := NewRowFilter(RowFilterConfig{Enforcer: e, Subject: SubjectFromContext(userKey), Models: []interface{}{&Book{}}})
+//	_ = db.Use(f)
+//	db.WithContext(context.WithValue(ctx, userKey, "alice")).Find(&books)
+type RowFilter struct {
+	config RowFilterConfig
+	mu     sync.RWMutex
+	models map[reflect.Type]bool
+}
+
+var _ gorm.Plugin = (*RowFilter)(nil)
+
+// NewRowFilter returns a RowFilter, register it with db.Use.
+func NewRowFilter(config RowFilterConfig) (*RowFilter, error) {
+	if config.Enforcer == nil {
+		return nil, errors.New("row filter needs an enforcer")
+	}
+	if config.Subject == nil {
+		return nil, errors.New("row filter needs a subject resolver")
+	}
+	if config.Action == "" {
+		config.Action = "read"
+	}
+	if config.Prefix == "" {
+		config.Prefix = defaultConditionPrefix
+	}
+	f := &RowFilter{config: config, models: make(map[reflect.Type]bool)}
+	f.Register(config.Models...)
+	return f, nil
+}
+
+// Register opts models in to filtering.
+func (f *RowFilter) Register(models ...interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, model := range models {
+		f.models[modelType(model)] = true
+	}
+}
+
+// Name implements gorm.Plugin.
+func (f *RowFilter) Name() string {
+	return rowFilterName
+}
+
+// Initialize implements gorm.Plugin.
+func (f *RowFilter) Initialize(db *gorm.DB) error {
+	if err := db.Callback().Query().Before("gorm:query").Register(rowFilterName, f.filter); err != nil {
+		return err
+	}
+	return db.Callback().Row().Before("gorm:row").Register(rowFilterName, f.filter)
+}
+
+// action returns the action to check for the model of s, and false if it is not filtered.
+func (f *RowFilter) action(s *schema.Schema) (string, bool) {
+	if model, ok := reflect.New(s.ModelType).Interface().(RowFiltered); ok {
+		if action := model.RowFilterAction(); action != "" {
+			return action, true
+		}
+		return f.config.Action, true
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.config.Action, f.models[s.ModelType]
+}
+
+func (f *RowFilter) filter(db *gorm.DB) {
+	stmt := db.Statement
+	if db.Error != nil || stmt.Schema == nil || stmt.SQL.Len() > 0 || authorizationSkipped(stmt.Context) {
+		return
+	}
+	action, ok := f.action(stmt.Schema)
+	if !ok {
+		return
+	}
+
+	subject, err := f.config.Subject(stmt.Context)
+	if err != nil {
+		_ = db.AddError(err)
+		return
+	}
+	var expr clause.Expression
+	conditions, err := f.config.Enforcer.GetAllowedObjectConditions(subject, action, f.config.Prefix)
+	switch {
+	case errors.Is(err, casbinerrors.ErrEmptyCondition):
+		expr = clause.Expr{SQL: "1 = 0"}
+	case err != nil:
+		_ = db.AddError(err)
+		return
+	default:
+		opts := ConditionOptions{Fields: f.config.Fields, Prefix: f.config.Prefix}
+		resolve, err := opts.schemaResolver(stmt.Schema)
+		if err == nil {
+			expr, err = CombineConditions(conditions, CombineTypeOr).parse(resolve)
+		}
+		if err != nil {
+			_ = db.AddError(err)
+			return
+		}
+	}
+	stmt.AddClause(clause.Where{Exprs: []clause.Expression{expr}})
+}
+
+// modelType returns the struct type of a model, a pointer to it or a slice of them.
+func modelType(model interface{}) reflect.Type {
+	t := reflect.TypeOf(model)
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	return t
+}
@@ -0,0 +1,76 @@
+// Copyright 2025 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gormadapter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/anzimu/casbin/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+type userKey struct{}
+
+// FilteredBook opts in to row filtering with the write action.
+type FilteredBook Book
+
+func (FilteredBook) TableName() string { return "books" }
+
+func (FilteredBook) RowFilterAction() string { return "write" }
+
+func TestRowFilter(t *testing.T) {
+	db := initBooks(t)
+	e, err := casbin.NewEnforcer("examples/object_conditions_model.conf", "examples/object_conditions_policy.csv")
+	assert.NoError(t, err)
+	// carol may write the books of author2.
+	_, err = e.AddPolicy("carol", "r.obj.author == 'author2'", "write")
+	assert.NoError(t, err)
+
+	f, err := NewRowFilter(RowFilterConfig{Enforcer: e, Subject: SubjectFromContext(userKey{}), Models: []interface{}{&Book{}}})
+	assert.NoError(t, err)
+	assert.NoError(t, db.Use(f))
+	alice := db.WithContext(context.WithValue(context.Background(), userKey{}, "alice"))
+	bob := db.WithContext(context.WithValue(context.Background(), userKey{}, "bob"))
+	carol := db.WithContext(context.WithValue(context.Background(), userKey{}, "carol"))
+
+	assert.Equal(t, []int{1, 2, 4, 6}, bookIDs(t, alice))
+	assert.Equal(t, []int{1, 2}, bookIDs(t, alice.Where("publisher = ?", "publisher1")))
+	var count int64
+	assert.NoError(t, alice.Model(&Book{}).Where("author = ?", "author3").Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+	rows, err := alice.Model(&Book{}).Rows()
+	assert.NoError(t, err)
+	n := 0
+	for rows.Next() {
+		n++
+	}
+	assert.NoError(t, rows.Close())
+	assert.Equal(t, 4, n)
+
+	// bob has no read conditions.
+	assert.Empty(t, bookIDs(t, bob))
+
+	var books []FilteredBook
+	assert.NoError(t, carol.Order("id").Find(&books).Error)
+	if assert.Len(t, books, 2) {
+		assert.Equal(t, 3, books[0].ID)
+	}
+	// bob's write condition "author = bob" is not a valid condition, the query fails instead of leaking rows.
+	assert.ErrorIs(t, bob.Find(&books).Error, ErrUntranslatableCondition)
+
+	assert.ErrorIs(t, db.Find(&[]Book{}).Error, ErrSubjectNotFound)
+	assert.Len(t, bookIDs(t, db.WithContext(SkipAuthorization(context.Background()))), 6)
+}
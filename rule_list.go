@@ -0,0 +1,150 @@
+// Copyright 2025 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gormadapter
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const defaultListLimit = 100
+
+// ErrInvalidCursor is returned by ListRules for a cursor it did not issue.
+var ErrInvalidCursor = errors.New("[casbin] gorm adapter error: invalid cursor")
+
+// Query selects a page of rules for ListRules.
+type Query struct {
+	// Filter and Expr restrict the rules, both are optional.
+	Filter Filter
+	Expr   FilterExpr
+	// OrderBy is the column to sort by, "ptype" or "v0"-"v7". Rules are sorted by ID by default
+	// and ties are broken by ID.
+	OrderBy string
+	Desc    bool
+	// Limit is the page size, 100 by default.
+	Limit int
+	// Cursor is the next-page cursor returned by the previous call, empty for the first page.
+	Cursor string
+}
+
+// cursor is the position after the last rule of a page.
+type cursor struct {
+	Value string `json:"v,omitempty"`
+	ID    uint   `json:"id"`
+}
+
+func encodeCursor(c cursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(s string) (cursor, error) {
+	var c cursor
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(b, &c); err != nil {
+		return c, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// ListRules returns a page of the rules with their IDs, and the cursor of the next page,
+// which is empty on the last page.
+//
+// Example:
+//
+//	rules, next, err := a.ListRules(ctx, Query{Filter: Filter{Ptype: []string{"p"}}, OrderBy: "v1", Limit: 50})
+//	rules, next, err = a.ListRules(ctx, Query{Filter: Filter{Ptype: []string{"p"}}, OrderBy: "v1", Limit: 50, Cursor: next})
+func (a *Adapter) ListRules(ctx context.Context, q Query) ([]CasbinRule, string, error) {
+	if q.OrderBy != "" && !ruleColumns[q.OrderBy] {
+		return nil, "", fmt.Errorf("unknown rule column %q in order", q.OrderBy)
+	}
+	limit := q.Limit
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+
+	db := a.listQuery(a.db.WithContext(ctx), q)
+	if q.Cursor != "" {
+		c, err := decodeCursor(q.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		db = db.Where(afterCursor(q.OrderBy, q.Desc, c))
+	}
+	var orderBy []clause.OrderByColumn
+	if q.OrderBy != "" {
+		orderBy = append(orderBy, clause.OrderByColumn{Column: clause.Column{Name: q.OrderBy}, Desc: q.Desc})
+	}
+	orderBy = append(orderBy, clause.OrderByColumn{Column: clause.Column{Name: "id"}, Desc: q.Desc})
+
+	// Fetch one more rule to know whether there is a next page.
+	var rules []CasbinRule
+	if err := db.Order(clause.OrderBy{Columns: orderBy}).Limit(limit + 1).Find(&rules).Error; err != nil {
+		return nil, "", err
+	}
+	if len(rules) <= limit {
+		return rules, "", nil
+	}
+	rules = rules[:limit]
+	last := rules[limit-1]
+	next := cursor{ID: last.ID}
+	if q.OrderBy != "" {
+		next.Value = last.toMap()[q.OrderBy].(string)
+	}
+	return rules, encodeCursor(next), nil
+}
+
+// CountRules returns the number of rules matching the filter of q, ignoring its order and paging.
+func (a *Adapter) CountRules(ctx context.Context, q Query) (int64, error) {
+	var count int64
+	err := a.listQuery(a.db.WithContext(ctx), q).Count(&count).Error
+	return count, err
+}
+
+func (a *Adapter) listQuery(db *gorm.DB, q Query) *gorm.DB {
+	db = db.Scopes(a.casbinRuleTable(), a.readScope(), a.validNow(), a.filterQuery(db, q.Filter))
+	if q.Expr != nil {
+		db = db.Scopes(a.exprQuery(q.Expr))
+	}
+	return db
+}
+
+// afterCursor matches the rules after the cursor c in the given order.
+func afterCursor(orderBy string, desc bool, c cursor) clause.Expression {
+	id := clause.Column{Name: "id"}
+	after := func(column clause.Column, value interface{}) clause.Expression {
+		if desc {
+			return clause.Lt{Column: column, Value: value}
+		}
+		return clause.Gt{Column: column, Value: value}
+	}
+	if orderBy == "" {
+		return after(id, c.ID)
+	}
+	column := clause.Column{Name: orderBy}
+	return clause.Or(
+		after(column, c.Value),
+		clause.And(clause.Eq{Column: column, Value: c.Value}, after(id, c.ID)),
+	)
+}
@@ -0,0 +1,69 @@
+// Copyright 2025 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gormadapter
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListRules(t *testing.T) {
+	db := openSqliteTestDB(t)
+	a, err := NewAdapterByDBWithOptions(db, "", "", WithAutoMigrate())
+	assert.NoError(t, err)
+	var rules [][]string
+	for i := 0; i < 7; i++ {
+		// Two rules per object, so that the order by v1 has ties.
+		rules = append(rules, []string{fmt.Sprintf("user%d", i), fmt.Sprintf("data%d", i/2), "read"})
+	}
+	assert.NoError(t, a.AddPolicies("p", "p", rules))
+	assert.NoError(t, a.AddPolicy("g", "g", []string{"alice", "admin"}))
+	ctx := context.Background()
+
+	count, err := a.CountRules(ctx, Query{Filter: Filter{Ptype: []string{"p"}}})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), count)
+
+	// Page through the p rules by v1 descending, three per page.
+	var subjects []string
+	q := Query{Filter: Filter{Ptype: []string{"p"}}, OrderBy: "v1", Desc: true, Limit: 3}
+	for pages := 0; ; pages++ {
+		assert.Less(t, pages, 3)
+		page, next, err := a.ListRules(ctx, q)
+		assert.NoError(t, err)
+		for _, rule := range page {
+			assert.NotZero(t, rule.ID)
+			subjects = append(subjects, rule.V0)
+		}
+		if next == "" {
+			break
+		}
+		q.Cursor = next
+	}
+	assert.Equal(t, []string{"user6", "user5", "user4", "user3", "user2", "user1", "user0"}, subjects)
+
+	page, next, err := a.ListRules(ctx, Query{Expr: Col("v0").Prefix("user1"), Limit: 3})
+	assert.NoError(t, err)
+	assert.Empty(t, next)
+	assert.Len(t, page, 1)
+
+	_, _, err = a.ListRules(ctx, Query{Cursor: "not a cursor"})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+	_, _, err = a.ListRules(ctx, Query{OrderBy: "id; drop table casbin_rule"})
+	assert.Error(t, err)
+}
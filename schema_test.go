@@ -0,0 +1,64 @@
+// Copyright 2025 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gormadapter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/anzimu/casbin/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func containsSQL(sqls []string, prefix string) bool {
+	for _, sql := range sqls {
+		if strings.HasPrefix(sql, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestWithSchemaPostgres(t *testing.T) {
+	db, recorder := openDryRunDB(t, "postgres")
+
+	a, err := NewAdapterByDBWithOptions(db, "cms", "casbin", WithSchema("authz"), WithAutoMigrate())
+	assert.NoError(t, err)
+	assert.Equal(t, "authz.cms_casbin", a.getFullTableName())
+	assert.True(t, containsSQL(recorder.sqls, `CREATE SCHEMA IF NOT EXISTS "authz"`), recorder.sqls)
+	assert.True(t, containsSQL(recorder.sqls, `CREATE TABLE "authz"."cms_casbin"`), recorder.sqls)
+	assert.True(t, containsSQL(recorder.sqls,
+		`CREATE UNIQUE INDEX "idx_authz_cms_casbin" ON "authz"."cms_casbin" ("ptype","v0","v1","v2","v3","v4","v5","v6","v7")`), recorder.sqls)
+
+	recorder.sqls = nil
+	assert.NoError(t, a.AddPolicy("p", "p", []string{"alice", "data1", "read"}))
+	assert.NoError(t, a.RemovePolicy("p", "p", []string{"alice", "data1", "read"}))
+	e, _ := casbin.NewEnforcer("examples/rbac_model.conf")
+	assert.NoError(t, a.LoadPolicy(e.GetModel()))
+	assert.NoError(t, a.truncateTable(db))
+	assert.True(t, containsSQL(recorder.sqls, `INSERT INTO "authz"."cms_casbin"`), recorder.sqls)
+	assert.True(t, containsSQL(recorder.sqls, `DELETE FROM "authz"."cms_casbin"`), recorder.sqls)
+	assert.True(t, containsSQL(recorder.sqls, `SELECT * FROM "authz"."cms_casbin"`), recorder.sqls)
+	assert.True(t, containsSQL(recorder.sqls, `truncate table "authz"."cms_casbin" RESTART IDENTITY`), recorder.sqls)
+}
+
+func TestWithSchemaValidation(t *testing.T) {
+	db, _ := openDryRunDB(t, "postgres")
+
+	_, err := NewAdapterByDBWithOptions(db, "", "", WithSchema("authz; drop schema public"))
+	assert.ErrorIs(t, err, ErrInvalidIdentifier)
+	_, err = NewAdapterByDBWithOptions(db, "", "other.casbin_rule", WithSchema("authz"))
+	assert.ErrorIs(t, err, ErrInvalidIdentifier)
+}
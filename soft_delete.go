@@ -0,0 +1,183 @@
+// Copyright 2025 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gormadapter
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const deletedAtColumn = "deleted_at"
+
+// ErrSoftDeleteDisabled is returned by the soft delete APIs of an adapter built without WithSoftDelete.
+var ErrSoftDeleteDisabled = errors.New("[casbin] gorm adapter error: soft delete is not enabled")
+
+// softDeleteColumns declares the soft delete column for the migrator.
+type softDeleteColumns struct {
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+// DeletedRule is a soft-deleted rule with the time it was deleted.
+type DeletedRule struct {
+	CasbinRule
+	DeletedAt time.Time
+}
+
+// WithSoftDelete makes the remove operations set the deleted_at column of the rules instead of
+// deleting them. Soft-deleted rules are not loaded, listed or counted, see ListDeleted, Restore and
+// PurgeDeleted to manage them, which with WithSource only see the rules of the adapter's source. SavePolicy still deletes the rules it replaces, and adding a rule
+// again drops its soft-deleted copy.
+func WithSoftDelete() Option {
+	return func(a *Adapter) error {
+		a.softDelete = true
+		return nil
+	}
+}
+
+// createSoftDeleteColumn adds the deleted_at column to the rule table if it does not exist yet.
+func (a *Adapter) createSoftDeleteColumn() error {
+	if !a.softDelete {
+		return nil
+	}
+	m := a.db.Table(a.getFullTableName()).Migrator()
+	if m.HasColumn(&softDeleteColumns{}, "DeletedAt") {
+		return nil
+	}
+	return m.AddColumn(&softDeleteColumns{}, "DeletedAt")
+}
+
+// liveScope restricts db to the rules that are not soft-deleted.
+func (a *Adapter) liveScope(db *gorm.DB) *gorm.DB {
+	if !a.softDelete {
+		return db
+	}
+	return db.Where(clause.Eq{Column: clause.Column{Name: deletedAtColumn}, Value: nil})
+}
+
+// deletedRules is the scope of the soft-deleted rules of the current tenant and, with WithSource, of the adapter's source.
+func (a *Adapter) deletedRules() func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return a.ownRows(db.Scopes(a.deletedCopies()))
+	}
+}
+
+// deletedCopies is the scope of the soft-deleted rules of the current tenant of every source,
+// which all hold the unique index.
+func (a *Adapter) deletedCopies() func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return a.tenantScope(db.Table(a.getFullTableName())).
+			Where(clause.Neq{Column: clause.Column{Name: deletedAtColumn}, Value: nil})
+	}
+}
+
+// deleteRows deletes the rules matched by db, or marks them deleted with WithSoftDelete.
+func (a *Adapter) deleteRows(db *gorm.DB) error {
+	return a.write(db, OperationDelete, nil, func(db *gorm.DB) *gorm.DB {
+		if !a.softDelete {
+			return db.Delete(a.getTableInstance())
+		}
+		return db.UpdateColumn(deletedAtColumn, time.Now().UTC())
+	}).Error
+}
+
+// dropDeletedCopies deletes the soft-deleted copies of lines, which would otherwise
+// violate the unique index when lines are inserted.
+func (a *Adapter) dropDeletedCopies(db *gorm.DB, lines []CasbinRule) error {
+	if !a.softDelete || len(lines) == 0 {
+		return nil
+	}
+	keys, ptypes := keysOf(lines)
+
+	var deleted []CasbinRule
+	if err := primary(db.Session(&gorm.Session{NewDB: true})).Scopes(a.deletedCopies()).
+		Where(clause.IN{Column: clause.Column{Name: "ptype"}, Values: ptypes}).Find(&deleted).Error; err != nil {
+		return err
+	}
+	var ids []uint
+	for _, line := range deleted {
+		if keys[ruleKey(line)] {
+			ids = append(ids, line.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	query := db.Session(&gorm.Session{NewDB: true}).Scopes(a.deletedCopies()).Where("id IN ?", ids)
+	return a.write(query, OperationDelete, nil, func(db *gorm.DB) *gorm.DB {
+		return db.Delete(a.getTableInstance())
+	}).Error
+}
+
+// keysOf returns the rule keys of lines and their distinct ptypes.
+func keysOf(lines []CasbinRule) (map[string]bool, []interface{}) {
+	keys := make(map[string]bool, len(lines))
+	seen := make(map[string]bool)
+	var ptypes []interface{}
+	for _, line := range lines {
+		keys[ruleKey(line)] = true
+		if !seen[line.Ptype] {
+			seen[line.Ptype] = true
+			ptypes = append(ptypes, line.Ptype)
+		}
+	}
+	return keys, ptypes
+}
+
+// ListDeleted returns the soft-deleted rules of the current tenant matched by filter,
+// most recently deleted first.
+func (a *Adapter) ListDeleted(ctx context.Context, filter Filter) ([]DeletedRule, error) {
+	if !a.softDelete {
+		return nil, ErrSoftDeleteDisabled
+	}
+	var rules []DeletedRule
+	err := a.db.WithContext(ctx).Scopes(a.deletedRules(), a.filterQuery(a.db, filter)).
+		Order(clause.OrderByColumn{Column: clause.Column{Name: deletedAtColumn}, Desc: true}).Order("id").
+		Find(&rules).Error
+	return rules, err
+}
+
+// Restore restores the soft-deleted rules of the current tenant matched by filter and returns
+// how many were restored. Reload the enforcers to see them.
+func (a *Adapter) Restore(ctx context.Context, filter Filter) (int64, error) {
+	if !a.softDelete {
+		return 0, ErrSoftDeleteDisabled
+	}
+	defer a.markWrite()
+	query := a.db.WithContext(ctx).Scopes(a.deletedRules(), a.filterQuery(a.db, filter))
+	result := a.write(query, OperationUpdate, nil, func(db *gorm.DB) *gorm.DB {
+		return db.UpdateColumn(deletedAtColumn, nil)
+	})
+	return result.RowsAffected, result.Error
+}
+
+// PurgeDeleted deletes the rules of the current tenant that were soft-deleted more than olderThan
+// ago and returns how many were deleted.
+func (a *Adapter) PurgeDeleted(ctx context.Context, olderThan time.Duration) (int64, error) {
+	if !a.softDelete {
+		return 0, ErrSoftDeleteDisabled
+	}
+	defer a.markWrite()
+	before := time.Now().UTC().Add(-olderThan)
+	query := a.db.WithContext(ctx).Scopes(a.deletedRules()).
+		Where(clause.Lte{Column: clause.Column{Name: deletedAtColumn}, Value: before})
+	result := a.write(query, OperationDelete, nil, func(db *gorm.DB) *gorm.DB {
+		return db.Delete(a.getTableInstance())
+	})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,126 @@
+// Copyright 2025 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gormadapter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/anzimu/casbin/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSoftDelete(t *testing.T) {
+	db := openSqliteTestDB(t)
+	a, err := NewAdapterByDBWithOptions(db, "", "", WithSoftDelete(), WithAutoMigrate())
+	assert.NoError(t, err)
+	e, err := casbin.NewEnforcer("examples/rbac_model.conf", a)
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	_, err = e.AddPolicies([][]string{{"alice", "data1", "read"}, {"bob", "data2", "write"}, {"carol", "data3", "read"}})
+	assert.NoError(t, err)
+	_, err = e.RemovePolicy("alice", "data1", "read")
+	assert.NoError(t, err)
+	_, err = e.RemoveFilteredPolicy(1, "data2")
+	assert.NoError(t, err)
+	_, err = e.UpdateFilteredPolicies([][]string{{"carol", "data3", "write"}}, 0, "carol")
+	assert.NoError(t, err)
+
+	assert.NoError(t, e.LoadPolicy())
+	testGetPolicy(t, e, [][]string{{"carol", "data3", "write"}})
+	count, err := a.CountRules(ctx, Query{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	deleted, err := a.ListDeleted(ctx, Filter{})
+	assert.NoError(t, err)
+	assert.Len(t, deleted, 3)
+	for _, rule := range deleted {
+		assert.False(t, rule.DeletedAt.IsZero())
+	}
+
+	restored, err := a.Restore(ctx, Filter{V0: []string{"alice", "bob"}})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), restored)
+	assert.NoError(t, e.LoadPolicy())
+	testGetPolicy(t, e, [][]string{{"alice", "data1", "read"}, {"bob", "data2", "write"}, {"carol", "data3", "write"}})
+
+	// Adding a rule again replaces its soft-deleted copy.
+	_, err = e.AddPolicy("carol", "data3", "read")
+	assert.NoError(t, err)
+	deleted, err = a.ListDeleted(ctx, Filter{})
+	assert.NoError(t, err)
+	assert.Empty(t, deleted)
+}
+
+func TestPurgeDeleted(t *testing.T) {
+	db := openSqliteTestDB(t)
+	a, err := NewAdapterByDBWithOptions(db, "", "", WithSoftDelete(), WithAutoMigrate())
+	assert.NoError(t, err)
+	ctx := context.Background()
+	assert.NoError(t, a.AddPolicies("p", "p", [][]string{{"alice", "data1", "read"}, {"bob", "data2", "write"}}))
+	assert.NoError(t, a.RemovePolicy("p", "p", []string{"alice", "data1", "read"}))
+
+	purged, err := a.PurgeDeleted(ctx, time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), purged)
+	purged, err = a.PurgeDeleted(ctx, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+	deleted, err := a.ListDeleted(ctx, Filter{})
+	assert.NoError(t, err)
+	assert.Empty(t, deleted)
+
+	plain, err := NewAdapterByDBWithOptions(db, "", "")
+	assert.NoError(t, err)
+	_, err = plain.Restore(ctx, Filter{})
+	assert.ErrorIs(t, err, ErrSoftDeleteDisabled)
+}
+
+func TestSoftDeleteSources(t *testing.T) {
+	db := openSqliteTestDB(t)
+	iac, err := NewAdapterByDBWithOptions(db, "", "", WithSource("iac"), WithSoftDelete(), WithAutoMigrate())
+	assert.NoError(t, err)
+	ui, err := NewAdapterByDBWithOptions(db, "", "", WithSource("ui"), WithSoftDelete())
+	assert.NoError(t, err)
+	ctx := context.Background()
+	assert.NoError(t, iac.AddPolicies("p", "p", [][]string{{"alice", "data1", "read"}, {"bob", "data2", "write"}}))
+	assert.NoError(t, ui.AddPolicy("p", "p", []string{"carol", "data1", "read"}))
+	assert.NoError(t, iac.RemovePolicies("p", "p", [][]string{{"alice", "data1", "read"}, {"bob", "data2", "write"}}))
+	assert.NoError(t, ui.RemovePolicy("p", "p", []string{"carol", "data1", "read"}))
+
+	// The ui only lists, restores and purges its own deleted rules.
+	deleted, err := ui.ListDeleted(ctx, Filter{})
+	assert.NoError(t, err)
+	if assert.Len(t, deleted, 1) {
+		assert.Equal(t, "carol", deleted[0].V0)
+	}
+	restored, err := ui.Restore(ctx, Filter{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), restored)
+	assert.NoError(t, ui.RemovePolicy("p", "p", []string{"carol", "data1", "read"}))
+	purged, err := ui.PurgeDeleted(ctx, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+	deleted, err = iac.ListDeleted(ctx, Filter{})
+	assert.NoError(t, err)
+	assert.Len(t, deleted, 2)
+
+	// A rule deleted by another source can still be added again.
+	assert.NoError(t, ui.AddPolicy("p", "p", []string{"alice", "data1", "read"}))
+	assert.Equal(t, map[string]string{"alice": "ui", "bob": "iac"}, sources(t, db))
+}
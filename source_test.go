@@ -0,0 +1,104 @@
+// Copyright 2025 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gormadapter
+
+import (
+	"testing"
+
+	"github.com/anzimu/casbin/v2"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// sources returns the source of each rule, keyed by its subject.
+func sources(t *testing.T, db *gorm.DB) map[string]string {
+	var rows []struct {
+		V0     string
+		Source string
+	}
+	assert.NoError(t, db.Table("casbin_rule").Select("v0, source").Scan(&rows).Error)
+	result := make(map[string]string)
+	for _, row := range rows {
+		result[row.V0] = row.Source
+	}
+	return result
+}
+
+func TestSource(t *testing.T) {
+	db := openSqliteTestDB(t)
+	iac, err := NewAdapterByDBWithOptions(db, "", "", WithSource("iac"), WithAutoMigrate())
+	assert.NoError(t, err)
+	ui, err := NewAdapterByDBWithOptions(db, "", "", WithSource("ui"))
+	assert.NoError(t, err)
+
+	assert.NoError(t, iac.AddPolicies("p", "p", [][]string{{"alice", "data1", "read"}, {"bob", "data2", "write"}}))
+	assert.NoError(t, ui.AddPolicy("p", "p", []string{"carol", "data1", "read"}))
+	assert.NoError(t, ui.AddPolicy("g", "g", []string{"dave", "alice"}))
+
+	// The ui loads every rule but only replaces its own.
+	e, err := casbin.NewEnforcer("examples/rbac_model.conf", ui)
+	assert.NoError(t, err)
+	assert.Len(t, e.GetPolicy(), 3)
+	e.EnableAutoSave(false)
+	_, err = e.RemovePolicy("carol", "data1", "read")
+	assert.NoError(t, err)
+	_, err = e.AddPolicy("erin", "data2", "read")
+	assert.NoError(t, err)
+	assert.NoError(t, e.SavePolicy())
+	assert.Equal(t, map[string]string{"alice": "iac", "bob": "iac", "dave": "ui", "erin": "ui"}, sources(t, db))
+
+	// The iac sync replaces its rules, the rules of the ui stay.
+	e, err = casbin.NewEnforcer("examples/rbac_model.conf")
+	assert.NoError(t, err)
+	e.SetAdapter(iac)
+	_, err = e.AddPolicy("frank", "data1", "write")
+	assert.NoError(t, err)
+	assert.NoError(t, e.SavePolicy())
+	assert.Equal(t, map[string]string{"frank": "iac", "dave": "ui", "erin": "ui"}, sources(t, db))
+
+	// Loads by source.
+	onlyUI, err := NewAdapterByDBWithOptions(db, "", "", WithLoadSources("ui"))
+	assert.NoError(t, err)
+	e, err = casbin.NewEnforcer("examples/rbac_model.conf", onlyUI)
+	assert.NoError(t, err)
+	testGetPolicy(t, e, [][]string{{"erin", "data2", "read"}})
+	assert.Equal(t, [][]string{{"dave", "alice"}}, e.GetGroupingPolicy())
+}
+
+func TestSourceOwnRules(t *testing.T) {
+	db := openSqliteTestDB(t)
+	iac, err := NewAdapterByDBWithOptions(db, "", "", WithSource("iac"), WithAutoMigrate())
+	assert.NoError(t, err)
+	ui, err := NewAdapterByDBWithOptions(db, "", "", WithSource("ui"))
+	assert.NoError(t, err)
+	assert.NoError(t, iac.AddPolicies("p", "p", [][]string{{"alice", "data1", "read"}, {"bob", "data2", "write"}}))
+	assert.NoError(t, ui.AddPolicy("p", "p", []string{"carol", "data1", "read"}))
+
+	// The ui neither removes nor updates the rules of the iac.
+	assert.NoError(t, ui.RemovePolicy("p", "p", []string{"alice", "data1", "read"}))
+	assert.NoError(t, ui.RemovePolicies("p", "p", [][]string{{"bob", "data2", "write"}}))
+	assert.NoError(t, ui.RemoveFilteredPolicy("p", "p", 1, "data2"))
+	assert.NoError(t, ui.UpdatePolicy("p", "p", []string{"alice", "data1", "read"}, []string{"alice", "data1", "write"}))
+	assert.NoError(t, ui.UpdatePolicies("p", "p", [][]string{{"bob", "data2", "write"}}, [][]string{{"bob", "data2", "read"}}))
+	removed, err := ui.UpdateFilteredPolicies("p", "p", [][]string{{"erin", "data1", "read"}}, 1, "data1")
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"p", "carol", "data1", "read"}}, removed)
+	assert.NoError(t, ui.RemoveFilteredPolicyByExpr(Col("v1").In("data1", "data2")))
+	assert.Equal(t, map[string]string{"alice": "iac", "bob": "iac"}, sources(t, db))
+
+	e, err := casbin.NewEnforcer("examples/rbac_model.conf", iac)
+	assert.NoError(t, err)
+	testGetPolicy(t, e, [][]string{{"alice", "data1", "read"}, {"bob", "data2", "write"}})
+}
@@ -0,0 +1,203 @@
+// Copyright 2025 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gormadapter
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+const (
+	// statsTopSubjects is the number of subjects reported in PolicyStats.TopSubjects.
+	statsTopSubjects = 10
+	// statsMaxRoleDepth bounds the role inheritance depth computed by Stats, a cycle reports it.
+	statsMaxRoleDepth = 100
+)
+
+// statsColumns are the rule columns reported by Stats.
+var statsColumns = []string{"ptype", "v0", "v1", "v2", "v3", "v4", "v5", "v6", "v7"}
+
+// PolicyStats is the cardinality report returned by Stats.
+type PolicyStats struct {
+	// Rows is the number of rules per ptype.
+	Rows map[string]int64
+	// Distinct is the number of distinct values of V0-V7 per ptype.
+	Distinct map[string][8]int64
+	// TopSubjects are the subjects (V0) with the most p rules, most first.
+	TopSubjects []SubjectCount
+	// RoleDepth is the length of the longest role inheritance chain per g ptype.
+	RoleDepth map[string]int
+	// Columns compares the longest value of each column with its declared size.
+	Columns []ColumnStats
+}
+
+// SubjectCount is the number of p rules of a subject.
+type SubjectCount struct {
+	Subject string
+	Rules   int64
+}
+
+// ColumnStats is the longest value of a column and the size declared by its gorm tag, 0 if unsized.
+type ColumnStats struct {
+	Name      string
+	MaxLength int
+	Size      int
+}
+
+type ptypeStats struct {
+	Ptype string
+	Total int64
+	D0    int64
+	D1    int64
+	D2    int64
+	D3    int64
+	D4    int64
+	D5    int64
+	D6    int64
+	D7    int64
+}
+
+type columnLengths struct {
+	Ptype int
+	V0    int
+	V1    int
+	V2    int
+	V3    int
+	V4    int
+	V5    int
+	V6    int
+	V7    int
+}
+
+// Stats computes a cardinality report of the rule table with aggregate SQL, without loading the policy.
+func (a *Adapter) Stats(ctx context.Context) (*PolicyStats, error) {
+	db := a.db.WithContext(ctx)
+	stats := &PolicyStats{
+		Rows:      make(map[string]int64),
+		Distinct:  make(map[string][8]int64),
+		RoleDepth: make(map[string]int),
+	}
+
+	var ptypes []ptypeStats
+	selects := []string{"ptype", "COUNT(*) AS total"}
+	for i := 0; i < 8; i++ {
+		selects = append(selects, fmt.Sprintf("COUNT(DISTINCT v%d) AS d%d", i, i))
+	}
+	if err := db.Scopes(a.casbinRuleTable(), a.readScope(), a.validNow()).
+		Select(strings.Join(selects, ", ")).Group("ptype").Order("ptype").Scan(&ptypes).Error; err != nil {
+		return nil, err
+	}
+	var policyTypes []string
+	for _, p := range ptypes {
+		stats.Rows[p.Ptype] = p.Total
+		stats.Distinct[p.Ptype] = [8]int64{p.D0, p.D1, p.D2, p.D3, p.D4, p.D5, p.D6, p.D7}
+		switch {
+		case strings.HasPrefix(p.Ptype, "p"):
+			policyTypes = append(policyTypes, p.Ptype)
+		case strings.HasPrefix(p.Ptype, "g"):
+			depth, err := a.roleDepth(db, p.Ptype)
+			if err != nil {
+				return nil, err
+			}
+			stats.RoleDepth[p.Ptype] = depth
+		}
+	}
+
+	if len(policyTypes) > 0 {
+		if err := db.Scopes(a.casbinRuleTable(), a.readScope(), a.validNow()).
+			Select("v0 AS subject, COUNT(*) AS rules").Where("ptype IN ?", policyTypes).
+			Group("v0").Order("rules DESC, v0").Limit(statsTopSubjects).
+			Scan(&stats.TopSubjects).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	columns, err := a.columnStats(db)
+	if err != nil {
+		return nil, err
+	}
+	stats.Columns = columns
+	return stats, nil
+}
+
+// roleDepth returns the length of the longest inheritance chain of the role links of ptype.
+// Links only chain within their domain.
+func (a *Adapter) roleDepth(db *gorm.DB, ptype string) (int, error) {
+	q := &reverseQuery{adapter: a, db: db}
+	q.add("WITH RECURSIVE chain(name, dom, depth) AS (SELECT ?, ?, 1",
+		clause.Column{Table: "g", Name: "v1"}, clause.Column{Table: "g", Name: "v2"})
+	q.from("g")
+	q.add(" WHERE ? = ?", clause.Column{Table: "g", Name: "ptype"}, ptype)
+	q.visible("g")
+	q.add(" UNION SELECT ?, ?, c.depth + 1", clause.Column{Table: "l", Name: "v1"}, clause.Column{Table: "l", Name: "v2"})
+	q.from("l")
+	q.add(" JOIN chain c ON ? = c.name AND ? = c.dom WHERE ? = ?",
+		clause.Column{Table: "l", Name: "v0"}, clause.Column{Table: "l", Name: "v2"},
+		clause.Column{Table: "l", Name: "ptype"}, ptype)
+	q.visible("l")
+	q.add(" AND c.depth < ?) SELECT MAX(depth) FROM chain", statsMaxRoleDepth)
+	if q.err != nil {
+		return 0, q.err
+	}
+	var depth int
+	err := db.Scopes(a.readScope()).Raw(q.sql.String(), q.vars...).Scan(&depth).Error
+	return depth, err
+}
+
+// columnStats returns the longest value of each rule column with its declared size.
+func (a *Adapter) columnStats(db *gorm.DB) ([]ColumnStats, error) {
+	var model interface{} = a.getTableInstance()
+	if a.customTableKey != nil {
+		model = a.customTableKey
+	}
+	s, err := schema.Parse(model, &sync.Map{}, a.db.NamingStrategy)
+	if err != nil {
+		return nil, err
+	}
+
+	length := "LENGTH"
+	switch db.Dialector.Name() {
+	case "mysql":
+		length = "CHAR_LENGTH"
+	case "sqlserver":
+		length = "LEN"
+	}
+	selects := make([]string, 0, len(statsColumns))
+	for _, column := range statsColumns {
+		selects = append(selects, fmt.Sprintf("COALESCE(MAX(%s(%s)), 0) AS %s", length, column, column))
+	}
+	var l columnLengths
+	if err := db.Scopes(a.casbinRuleTable(), a.readScope(), a.validNow()).
+		Select(strings.Join(selects, ", ")).Scan(&l).Error; err != nil {
+		return nil, err
+	}
+
+	lengths := []int{l.Ptype, l.V0, l.V1, l.V2, l.V3, l.V4, l.V5, l.V6, l.V7}
+	columns := make([]ColumnStats, 0, len(statsColumns))
+	for i, column := range statsColumns {
+		c := ColumnStats{Name: column, MaxLength: lengths[i]}
+		if field := s.LookUpField(column); field != nil {
+			c.Size = field.Size
+		}
+		columns = append(columns, c)
+	}
+	return columns, nil
+}
@@ -0,0 +1,43 @@
+// Copyright 2025 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gormadapter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStats(t *testing.T) {
+	db := openSqliteTestDB(t)
+	a, err := NewAdapterByDBWithOptions(db, "", "", WithAutoMigrate())
+	assert.NoError(t, err)
+	initPolicy(t, a)
+	assert.NoError(t, a.AddPolicies("g", "g", [][]string{{"carol", "alice"}, {"dave", "carol"}}))
+	// A cycle is bounded by the maximum depth.
+	assert.NoError(t, a.AddPolicies("g2", "g2", [][]string{{"a", "b"}, {"b", "a"}}))
+
+	stats, err := a.Stats(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int64{"p": 4, "g": 3, "g2": 2}, stats.Rows)
+	assert.Equal(t, [8]int64{3, 2, 2, 1, 1, 1, 1, 1}, stats.Distinct["p"])
+	assert.Equal(t, []SubjectCount{{"data2_admin", 2}, {"alice", 1}, {"bob", 1}}, stats.TopSubjects)
+	assert.Equal(t, map[string]int{"g": 3, "g2": statsMaxRoleDepth}, stats.RoleDepth)
+
+	assert.Len(t, stats.Columns, 9)
+	assert.Equal(t, ColumnStats{Name: "v0", MaxLength: len("data2_admin"), Size: 100}, stats.Columns[1])
+	assert.Equal(t, ColumnStats{Name: "v7", MaxLength: 0, Size: 25}, stats.Columns[8])
+}
@@ -0,0 +1,112 @@
+// Copyright 2025 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gormadapter
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrTenantNotFound = errors.New("[casbin] gorm adapter error: tenant not found")
+
+// TenantResolver returns the tenant that rule rows are scoped to for the given context.
+type TenantResolver func(ctx context.Context) (string, error)
+
+// FixedTenant returns a TenantResolver that always resolves to tenant.
+func FixedTenant(tenant string) TenantResolver {
+	return func(ctx context.Context) (string, error) {
+		return tenant, nil
+	}
+}
+
+// TenantFromContext returns a TenantResolver that reads a string tenant stored under key in the context.
+// It is meant to be used with ContextAdapter, whose *Ctx methods run their queries with the given context.
+func TenantFromContext(key interface{}) TenantResolver {
+	return func(ctx context.Context) (string, error) {
+		if ctx == nil {
+			return "", ErrTenantNotFound
+		}
+		tenant, ok := ctx.Value(key).(string)
+		if !ok || tenant == "" {
+			return "", ErrTenantNotFound
+		}
+		return tenant, nil
+	}
+}
+
+// WithTenant scopes every query, insert and truncate of the adapter to the tenant returned by resolver,
+// which is stored in column.
+func WithTenant(column string, resolver TenantResolver) Option {
+	return func(a *Adapter) error {
+		if column == "" || resolver == nil {
+			return errors.New("tenant column and resolver must not be empty")
+		}
+		if err := validateIdentifier(column); err != nil {
+			return err
+		}
+		a.tenantColumn = column
+		a.tenantResolver = resolver
+		return nil
+	}
+}
+
+// resolveTenant returns the tenant of the statement, or "" if the adapter is not multi-tenant.
+func (a *Adapter) resolveTenant(db *gorm.DB) (string, error) {
+	if a.tenantColumn == "" {
+		return "", nil
+	}
+	ctx := db.Statement.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return a.tenantResolver(ctx)
+}
+
+// tenantScope restricts db to the rows of the current tenant.
+func (a *Adapter) tenantScope(db *gorm.DB) *gorm.DB {
+	if a.tenantColumn == "" {
+		return db
+	}
+	tenant, err := a.resolveTenant(db)
+	if err != nil {
+		_ = db.AddError(err)
+		return db
+	}
+	return db.Where(clause.Eq{Column: clause.Column{Name: a.tenantColumn}, Value: tenant})
+}
+
+// createTenantColumn adds the tenant column to the rule table if it does not exist yet.
+func (a *Adapter) createTenantColumn() error {
+	if a.tenantColumn == "" {
+		return nil
+	}
+	return a.ensureColumn(a.tenantColumn, "VARCHAR(100) NOT NULL DEFAULT ''")
+}
+
+// tenantCondition returns the condition restricting the rule table aliased as alias to the current tenant,
+// or nil if the adapter is not multi-tenant.
+func (a *Adapter) tenantCondition(db *gorm.DB, alias string) (clause.Expression, error) {
+	if a.tenantColumn == "" {
+		return nil, nil
+	}
+	tenant, err := a.resolveTenant(db)
+	if err != nil {
+		return nil, err
+	}
+	return clause.Eq{Column: clause.Column{Table: alias, Name: a.tenantColumn}, Value: tenant}, nil
+}
@@ -0,0 +1,172 @@
+// Copyright 2025 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gormadapter
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// tenantIDPattern is the set of tenant IDs that can safely be used as a table prefix.
+var tenantIDPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]{0,62}$`)
+
+// TenantTableManager gives every tenant its own rule table named "<tenant>_<tableName>".
+// Tables are created on first use and the per-tenant adapters are cached.
+type TenantTableManager struct {
+	db        *gorm.DB
+	tableName string
+	opts      []Option
+
+	mu       sync.Mutex
+	adapters map[string]*Adapter
+}
+
+// NewTenantTableManager creates a TenantTableManager on an existing Gorm instance.
+// opts are applied to every per-tenant adapter.
+func NewTenantTableManager(db *gorm.DB, tableName string, opts ...Option) *TenantTableManager {
+	if len(tableName) == 0 {
+		tableName = defaultTableName
+	}
+	return &TenantTableManager{
+		db:        db,
+		tableName: tableName,
+		opts:      opts,
+		adapters:  make(map[string]*Adapter),
+	}
+}
+
+func validateTenantID(id string) error {
+	if !tenantIDPattern.MatchString(id) {
+		return fmt.Errorf("invalid tenant id %q: only letters, digits and \"_\" are allowed", id)
+	}
+	return nil
+}
+
+// ForTenant returns the adapter of the tenant, creating and migrating its table on first use.
+func (m *TenantTableManager) ForTenant(id string) (*Adapter, error) {
+	if err := validateTenantID(id); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if a, ok := m.adapters[id]; ok {
+		return a, nil
+	}
+
+	opts := make([]Option, 0, len(m.opts)+1)
+	opts = append(opts, m.opts...)
+	opts = append(opts, WithAutoMigrate())
+	a, err := NewAdapterByDBWithOptions(m.db, id, m.tableName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	m.adapters[id] = a
+	return a, nil
+}
+
+// tableOf returns an unconnected adapter of the tenant, which names the rule table like its adapter.
+func (m *TenantTableManager) tableOf(id string) (*Adapter, error) {
+	a := &Adapter{tablePrefix: id, tableName: m.tableName, db: m.db}
+	for _, opt := range m.opts {
+		if err := opt(a); err != nil {
+			return nil, err
+		}
+	}
+	return a, validateTableName(a.getFullTableName())
+}
+
+// ListTenants returns the tenants that have a rule table in the database, sorted by ID.
+// Only the tables of the schema of the options that look like rule tables are considered.
+func (m *TenantTableManager) ListTenants() ([]string, error) {
+	a, err := m.tableOf("")
+	if err != nil {
+		return nil, err
+	}
+	tables, err := m.tables(a.schema)
+	if err != nil {
+		return nil, err
+	}
+
+	suffix := "_" + m.tableName
+	tenants := make([]string, 0)
+	for _, table := range tables {
+		if !strings.HasSuffix(table, suffix) {
+			continue
+		}
+		id := strings.TrimSuffix(table, suffix)
+		if validateTenantID(id) != nil {
+			continue
+		}
+		tenant, err := m.tableOf(id)
+		if err != nil {
+			continue
+		}
+		if m.isRuleTable(tenant.getFullTableName()) {
+			tenants = append(tenants, id)
+		}
+	}
+	sort.Strings(tenants)
+	return tenants, nil
+}
+
+// tables returns the names of the tables of schema, or of the current schema if it is empty.
+func (m *TenantTableManager) tables(schema string) ([]string, error) {
+	if schema == "" {
+		return m.db.Migrator().GetTables()
+	}
+	var tables []string
+	var err error
+	if isSqlite(m.db) {
+		err = m.db.Raw("SELECT name FROM ?.sqlite_master WHERE type = 'table'", clause.Table{Name: schema}).Scan(&tables).Error
+	} else {
+		err = m.db.Raw("SELECT table_name FROM information_schema.tables WHERE table_schema = ? AND table_type = 'BASE TABLE'",
+			schema).Scan(&tables).Error
+	}
+	return tables, err
+}
+
+// isRuleTable reports whether table has the columns of a rule table.
+func (m *TenantTableManager) isRuleTable(table string) bool {
+	var lines []CasbinRule
+	return m.db.Table(table).Select("id", "ptype", "v0", "v7").Limit(1).Find(&lines).Error == nil
+}
+
+// DropTenant drops the rule table of the tenant and forgets its adapter.
+func (m *TenantTableManager) DropTenant(id string) error {
+	if err := validateTenantID(id); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	a, err := m.tableOf(id)
+	if err != nil {
+		return err
+	}
+	if err := m.db.Migrator().DropTable(a.getFullTableName()); err != nil {
+		return err
+	}
+	delete(m.adapters, id)
+	return nil
+}
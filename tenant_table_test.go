@@ -0,0 +1,91 @@
+// Copyright 2025 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gormadapter
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/anzimu/casbin/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTenantTableManager(t *testing.T) {
+	db := openSqliteTestDB(t)
+	m := NewTenantTableManager(db, "")
+
+	a1, err := m.ForTenant("acme")
+	assert.NoError(t, err)
+	a2, err := m.ForTenant("globex")
+	assert.NoError(t, err)
+	cached, err := m.ForTenant("acme")
+	assert.NoError(t, err)
+	assert.Same(t, a1, cached)
+
+	initPolicy(t, a1)
+	e, _ := casbin.NewEnforcer("examples/rbac_model.conf", a2)
+	testGetPolicy(t, e, [][]string{})
+	assert.True(t, db.Migrator().HasTable("acme_casbin_rule"))
+
+	tenants, err := m.ListTenants()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"acme", "globex"}, tenants)
+
+	assert.NoError(t, m.DropTenant("acme"))
+	assert.False(t, db.Migrator().HasTable("acme_casbin_rule"))
+	tenants, err = m.ListTenants()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"globex"}, tenants)
+
+	for _, id := range []string{"", "a;drop table x", "a b", "x\"y", "1abc"} {
+		_, err = m.ForTenant(id)
+		assert.Error(t, err, id)
+		assert.Error(t, m.DropTenant(id), id)
+	}
+}
+
+func TestTenantTableManagerSchema(t *testing.T) {
+	db := openSqliteTestDB(t)
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	// An attached database only exists on the connection that attached it.
+	sqlDB.SetMaxOpenConns(1)
+	assert.NoError(t, db.Exec("ATTACH DATABASE ? AS authz", filepath.Join(t.TempDir(), "authz.db")).Error)
+
+	// Neither a table of another schema nor a table without the rule columns is a tenant.
+	assert.NoError(t, db.Exec("CREATE TABLE audit_casbin_rule (id INTEGER PRIMARY KEY, message TEXT)").Error)
+	plain := NewTenantTableManager(db, "")
+	_, err = plain.ForTenant("initech")
+	assert.NoError(t, err)
+
+	m := NewTenantTableManager(db, "", WithSchema("authz"))
+	a, err := m.ForTenant("acme")
+	assert.NoError(t, err)
+	assert.NoError(t, a.AddPolicy("p", "p", []string{"alice", "data1", "read"}))
+	assert.NoError(t, db.Exec("CREATE TABLE authz.audit_casbin_rule (id INTEGER PRIMARY KEY, message TEXT)").Error)
+
+	tenants, err := m.ListTenants()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"acme"}, tenants)
+	tenants, err = plain.ListTenants()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"initech"}, tenants)
+
+	assert.NoError(t, m.DropTenant("acme"))
+	tenants, err = m.ListTenants()
+	assert.NoError(t, err)
+	assert.Empty(t, tenants)
+	assert.True(t, db.Migrator().HasTable("initech_casbin_rule"))
+}
@@ -0,0 +1,75 @@
+// Copyright 2025 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gormadapter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/anzimu/casbin/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+type tenantCtxKey struct{}
+
+func TestAdapterWithFixedTenant(t *testing.T) {
+	db := openSqliteTestDB(t)
+
+	a1, err := NewAdapterByDBWithOptions(db, "", "", WithTenant("tenant", FixedTenant("t1")), WithAutoMigrate())
+	assert.NoError(t, err)
+	a2, err := NewAdapterByDBWithOptions(db, "", "", WithTenant("tenant", FixedTenant("t2")), WithAutoMigrate())
+	assert.NoError(t, err)
+
+	initPolicy(t, a1)
+	assert.NoError(t, a2.AddPolicy("p", "p", []string{"alice", "data1", "read"}))
+
+	e, _ := casbin.NewEnforcer("examples/rbac_model.conf", a2)
+	testGetPolicy(t, e, [][]string{{"alice", "data1", "read"}})
+
+	// Saving tenant t2 must not wipe the rules of tenant t1.
+	e.ClearPolicy()
+	assert.NoError(t, e.SavePolicy())
+	e, _ = casbin.NewEnforcer("examples/rbac_model.conf", a1)
+	testGetPolicy(t, e, [][]string{{"alice", "data1", "read"}, {"bob", "data2", "write"}, {"data2_admin", "data2", "read"}, {"data2_admin", "data2", "write"}})
+
+	assert.NoError(t, a2.RemoveFilteredPolicy("p", "p", 0, "alice"))
+	assert.NoError(t, e.LoadPolicy())
+	testGetPolicy(t, e, [][]string{{"alice", "data1", "read"}, {"bob", "data2", "write"}, {"data2_admin", "data2", "read"}, {"data2_admin", "data2", "write"}})
+}
+
+func TestContextAdapterWithTenantFromContext(t *testing.T) {
+	db := openSqliteTestDB(t)
+
+	ca, err := NewContextAdapterByDBWithOptions("db", db, "", "", WithTenant("tenant", TenantFromContext(tenantCtxKey{})), WithAutoMigrate())
+	assert.NoError(t, err)
+
+	ctx1 := context.WithValue(context.WithValue(context.Background(), "db", db), tenantCtxKey{}, "t1")
+	ctx2 := context.WithValue(context.WithValue(context.Background(), "db", db), tenantCtxKey{}, "t2")
+	assert.NoError(t, ca.AddPolicyCtx(ctx1, "p", "p", []string{"alice", "data1", "read"}))
+	assert.NoError(t, ca.AddPolicyCtx(ctx2, "p", "p", []string{"bob", "data2", "write"}))
+
+	e, _ := casbin.NewEnforcer("examples/rbac_model.conf")
+	assert.NoError(t, ca.LoadPolicyCtx(ctx1, e.GetModel()))
+	testGetPolicy(t, e, [][]string{{"alice", "data1", "read"}})
+
+	e.ClearPolicy()
+	assert.NoError(t, ca.LoadPolicyCtx(ctx2, e.GetModel()))
+	testGetPolicy(t, e, [][]string{{"bob", "data2", "write"}})
+
+	// Without a tenant in the context nothing is read or written.
+	ctx := context.WithValue(context.Background(), "db", db)
+	assert.ErrorIs(t, ca.LoadPolicyCtx(ctx, e.GetModel()), ErrTenantNotFound)
+	assert.ErrorIs(t, ca.AddPolicyCtx(ctx, "p", "p", []string{"eve", "data1", "read"}), ErrTenantNotFound)
+}
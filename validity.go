@@ -0,0 +1,278 @@
+// Copyright 2025 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gormadapter
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	validFromColumn = "valid_from"
+	expiresAtColumn = "expires_at"
+
+	defaultJanitorInterval  = time.Minute
+	defaultJanitorBatchSize = 1000
+)
+
+// ErrValidityDisabled is returned by the time-bounded APIs of an adapter built without WithValidity.
+var ErrValidityDisabled = errors.New("[casbin] gorm adapter error: validity columns are not enabled")
+
+// ErrInvalidValidity is returned when a rule would expire before it becomes valid.
+var ErrInvalidValidity = errors.New("[casbin] gorm adapter error: expiresAt must be after validFrom")
+
+// validityColumns declares the validity columns for the migrator.
+type validityColumns struct {
+	ValidFrom *time.Time
+	ExpiresAt *time.Time `gorm:"index"`
+}
+
+// WithValidity enables time-bounded rules: the optional valid_from and expires_at columns bound the time
+// in which a rule is loaded, see AddPolicyFor, AddPolicyUntil and AddPolicyBetween. Rules without bounds
+// are always valid. Use a Janitor to delete the expired rules.
+func WithValidity() Option {
+	return func(a *Adapter) error {
+		a.validity = true
+		return nil
+	}
+}
+
+// createValidityColumns adds the validity columns to the rule table if they do not exist yet.
+func (a *Adapter) createValidityColumns() error {
+	if !a.validity {
+		return nil
+	}
+	m := a.db.Table(a.getFullTableName()).Migrator()
+	for _, field := range []string{"ValidFrom", "ExpiresAt"} {
+		if m.HasColumn(&validityColumns{}, field) {
+			continue
+		}
+		if err := m.AddColumn(&validityColumns{}, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validityScope restricts db to the rules valid at now.
+func (a *Adapter) validityScope(db *gorm.DB, now time.Time) *gorm.DB {
+	if !a.validity {
+		return db
+	}
+	return db.Where(validityCondition("", now))
+}
+
+// validNow is the scope of the rules valid now, see validityScope.
+func (a *Adapter) validNow() func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return a.validityScope(db, time.Now())
+	}
+}
+
+// validityCondition is the condition on the rule table aliased as table that its rules are valid at now.
+func validityCondition(table string, now time.Time) clause.Expression {
+	now = now.UTC()
+	validFrom, expiresAt := clause.Column{Table: table, Name: validFromColumn}, clause.Column{Table: table, Name: expiresAtColumn}
+	return clause.Expr{
+		SQL:  "(? IS NULL OR ? <= ?) AND (? IS NULL OR ? > ?)",
+		Vars: []interface{}{validFrom, validFrom, now, expiresAt, expiresAt, now},
+	}
+}
+
+// dropInvalidCopies deletes the copies of lines that are expired but not purged yet or not valid yet,
+// which lines replace. They would otherwise violate the unique index when lines are inserted.
+func (a *Adapter) dropInvalidCopies(db *gorm.DB, lines []CasbinRule) error {
+	if !a.validity || len(lines) == 0 {
+		return nil
+	}
+	keys, ptypes := keysOf(lines)
+	invalid := clause.Expr{SQL: "NOT (?)", Vars: []interface{}{validityCondition("", time.Now())}}
+
+	var copies []CasbinRule
+	if err := primary(db.Session(&gorm.Session{NewDB: true})).Scopes(a.casbinRuleTable()).
+		Where(clause.IN{Column: clause.Column{Name: "ptype"}, Values: ptypes}).Where(invalid).Find(&copies).Error; err != nil {
+		return err
+	}
+	var ids []uint
+	for _, line := range copies {
+		if keys[ruleKey(line)] {
+			ids = append(ids, line.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	query := db.Session(&gorm.Session{NewDB: true}).Scopes(a.casbinRuleTable()).Where("id IN ?", ids)
+	return a.write(query, OperationDelete, nil, func(db *gorm.DB) *gorm.DB {
+		return db.Delete(a.getTableInstance())
+	}).Error
+}
+
+// AddPolicyFor adds a policy rule to the storage that is valid from now on for d, which must be positive.
+func (a *Adapter) AddPolicyFor(sec string, ptype string, rule []string, d time.Duration) error {
+	if d <= 0 {
+		return ErrInvalidValidity
+	}
+	now := time.Now()
+	return a.AddPolicyBetween(sec, ptype, rule, now, now.Add(d))
+}
+
+// AddPolicyUntil adds a policy rule to the storage that is valid until expiresAt.
+func (a *Adapter) AddPolicyUntil(sec string, ptype string, rule []string, expiresAt time.Time) error {
+	return a.addPolicyBetween(a.db, ptype, rule, nil, &expiresAt)
+}
+
+// AddPolicyBetween adds a policy rule to the storage that is valid from validFrom until expiresAt,
+// which must be after validFrom.
+func (a *Adapter) AddPolicyBetween(sec string, ptype string, rule []string, validFrom, expiresAt time.Time) error {
+	return a.addPolicyBetween(a.db, ptype, rule, &validFrom, &expiresAt)
+}
+
+func (a *Adapter) addPolicyBetween(db *gorm.DB, ptype string, rule []string, validFrom, expiresAt *time.Time) error {
+	if !a.validity {
+		return ErrValidityDisabled
+	}
+	if validFrom != nil && !expiresAt.After(*validFrom) {
+		return ErrInvalidValidity
+	}
+	defer a.markWrite()
+	extra := map[string]interface{}{expiresAtColumn: expiresAt.UTC()}
+	if validFrom != nil {
+		extra[validFromColumn] = validFrom.UTC()
+	}
+	line := a.savePolicyLine(ptype, rule)
+	return a.createLinesWith(db.Scopes(a.casbinRuleTable()), []CasbinRule{line}, extra)
+}
+
+// PurgeExpired deletes the expired rules of every tenant and source in batches of batchSize rows
+// and returns how many were deleted.
+func (a *Adapter) PurgeExpired(ctx context.Context, batchSize int) (int64, error) {
+	if !a.validity {
+		return 0, ErrValidityDisabled
+	}
+	if batchSize <= 0 {
+		batchSize = defaultJanitorBatchSize
+	}
+	defer a.markWrite()
+	table := a.getFullTableName()
+	limit := batchSize
+	if a.dryRun != nil {
+		// Nothing is deleted, so the expired rules are reported in a single batch.
+		limit = -1
+	}
+	var purged int64
+	for {
+		now := time.Now().UTC()
+		var ids []uint
+		if err := primary(a.db.WithContext(ctx)).Table(table).Where(clause.Lte{Column: clause.Column{Name: expiresAtColumn}, Value: now}).
+			Order("id").Limit(limit).Pluck("id", &ids).Error; err != nil {
+			return purged, err
+		}
+		if len(ids) == 0 {
+			return purged, nil
+		}
+		query := a.db.WithContext(ctx).Table(table).Where("id IN ?", ids)
+		result := a.write(query, OperationDelete, nil, func(db *gorm.DB) *gorm.DB {
+			return db.Delete(a.getTableInstance())
+		})
+		if result.Error != nil {
+			return purged, result.Error
+		}
+		purged += result.RowsAffected
+		if a.dryRun != nil || len(ids) < batchSize {
+			return purged, nil
+		}
+	}
+}
+
+// JanitorConfig configures a Janitor.
+type JanitorConfig struct {
+	// Interval is the time between two purges, a minute by default.
+	Interval time.Duration
+	// BatchSize is the number of rows deleted per statement, 1000 by default.
+	BatchSize int
+	// OnPurge is called with the number of deleted rules after a purge that deleted any,
+	// e.g. to reload the enforcers.
+	OnPurge func(purged int64)
+	// OnError is called with the errors of the purges.
+	OnError func(err error)
+}
+
+// Janitor is a goroutine that periodically deletes the expired rules of an adapter.
+//
+// Example:
+//
+//	j, _ := NewJanitor(a, JanitorConfig{OnPurge: func(int64) { _ = e.LoadPolicy() }})
+//	defer j.Stop()
+type Janitor struct {
+	adapter  *Adapter
+	config   JanitorConfig
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewJanitor starts a Janitor for the adapter, which must be built with WithValidity.
+func NewJanitor(a *Adapter, config JanitorConfig) (*Janitor, error) {
+	if !a.validity {
+		return nil, ErrValidityDisabled
+	}
+	if config.Interval <= 0 {
+		config.Interval = defaultJanitorInterval
+	}
+	j := &Janitor{adapter: a, config: config, stop: make(chan struct{}), done: make(chan struct{})}
+	go j.run()
+	return j, nil
+}
+
+func (j *Janitor) run() {
+	defer close(j.done)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-j.stop
+		cancel()
+	}()
+
+	ticker := time.NewTicker(j.config.Interval)
+	defer ticker.Stop()
+	for {
+		purged, err := j.adapter.PurgeExpired(ctx, j.config.BatchSize)
+		if err != nil && ctx.Err() == nil && j.config.OnError != nil {
+			j.config.OnError(err)
+		}
+		if purged > 0 && j.config.OnPurge != nil {
+			j.config.OnPurge(purged)
+		}
+		select {
+		case <-j.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Stop stops the Janitor and waits for the running purge to finish.
+func (j *Janitor) Stop() {
+	j.stopOnce.Do(func() {
+		close(j.stop)
+	})
+	<-j.done
+}
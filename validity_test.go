@@ -0,0 +1,157 @@
+// Copyright 2025 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gormadapter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/anzimu/casbin/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidity(t *testing.T) {
+	db := openSqliteTestDB(t)
+	a, err := NewAdapterByDBWithOptions(db, "", "", WithValidity(), WithAutoMigrate())
+	assert.NoError(t, err)
+	now := time.Now()
+
+	assert.NoError(t, a.AddPolicy("p", "p", []string{"alice", "data1", "read"}))
+	assert.NoError(t, a.AddPolicyFor("p", "p", []string{"alice", "prod-db", "read"}, 4*time.Hour))
+	assert.NoError(t, a.AddPolicyUntil("p", "p", []string{"bob", "prod-db", "read"}, now.Add(-time.Minute)))
+	assert.NoError(t, a.AddPolicyBetween("p", "p", []string{"carol", "prod-db", "read"}, now.Add(time.Hour), now.Add(2*time.Hour)))
+
+	e, err := casbin.NewEnforcer("examples/rbac_model.conf", a)
+	assert.NoError(t, err)
+	testGetPolicy(t, e, [][]string{{"alice", "data1", "read"}, {"alice", "prod-db", "read"}})
+	assert.NoError(t, e.LoadFilteredPolicy(Filter{V0: []string{"bob", "carol"}}))
+	assert.Empty(t, e.GetPolicy())
+
+	plain, err := NewAdapterByDBWithOptions(db, "", "")
+	assert.NoError(t, err)
+	assert.ErrorIs(t, plain.AddPolicyFor("p", "p", []string{"dave", "data1", "read"}, time.Hour), ErrValidityDisabled)
+	_, err = NewJanitor(plain, JanitorConfig{})
+	assert.ErrorIs(t, err, ErrValidityDisabled)
+}
+
+func TestPurgeExpired(t *testing.T) {
+	db := openSqliteTestDB(t)
+	a, err := NewAdapterByDBWithOptions(db, "", "", WithValidity(), WithAutoMigrate())
+	assert.NoError(t, err)
+	past := time.Now().Add(-time.Minute)
+	for _, user := range []string{"u1", "u2", "u3"} {
+		assert.NoError(t, a.AddPolicyUntil("p", "p", []string{user, "data1", "read"}, past))
+	}
+	assert.NoError(t, a.AddPolicyFor("p", "p", []string{"u4", "data1", "read"}, time.Hour))
+
+	purged, err := a.PurgeExpired(context.Background(), 2)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), purged)
+	count, err := a.CountRules(context.Background(), Query{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestJanitor(t *testing.T) {
+	db := openSqliteTestDB(t)
+	a, err := NewAdapterByDBWithOptions(db, "", "", WithValidity(), WithAutoMigrate())
+	assert.NoError(t, err)
+	assert.NoError(t, a.AddPolicyFor("p", "p", []string{"alice", "prod-db", "read"}, 50*time.Millisecond))
+	e, err := casbin.NewEnforcer("examples/rbac_model.conf", a)
+	assert.NoError(t, err)
+	assert.Len(t, e.GetPolicy(), 1)
+
+	purges := make(chan int64, 1)
+	j, err := NewJanitor(a, JanitorConfig{Interval: 10 * time.Millisecond, OnPurge: func(purged int64) {
+		assert.NoError(t, e.LoadPolicy())
+		purges <- purged
+	}})
+	assert.NoError(t, err)
+	defer j.Stop()
+
+	select {
+	case purged := <-purges:
+		assert.Equal(t, int64(1), purged)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the expired rule was not purged")
+	}
+	assert.Empty(t, e.GetPolicy())
+}
+
+func TestValiditySavePolicy(t *testing.T) {
+	db := openSqliteTestDB(t)
+	a, err := NewAdapterByDBWithOptions(db, "", "", WithValidity(), WithAutoMigrate())
+	assert.NoError(t, err)
+	now := time.Now()
+	assert.NoError(t, a.AddPolicy("p", "p", []string{"alice", "data1", "read"}))
+	assert.NoError(t, a.AddPolicyFor("p", "p", []string{"bob", "data1", "read"}, time.Hour))
+	assert.NoError(t, a.AddPolicyBetween("p", "p", []string{"carol", "data1", "read"}, now.Add(time.Hour), now.Add(2*time.Hour)))
+
+	e, err := casbin.NewEnforcer("examples/rbac_model.conf", a)
+	assert.NoError(t, err)
+	testGetPolicy(t, e, [][]string{{"alice", "data1", "read"}, {"bob", "data1", "read"}})
+	assert.NoError(t, e.SavePolicy())
+
+	var rules []savedRule
+	assert.NoError(t, db.Table("casbin_rule").Order("v0").Find(&rules).Error)
+	if assert.Len(t, rules, 3) {
+		assert.Nil(t, rules[0].ExpiresAt)
+		// The loaded time-bounded rule keeps its bounds and the rule that is not valid yet stays.
+		if assert.NotNil(t, rules[1].ExpiresAt) {
+			assert.WithinDuration(t, now.Add(time.Hour), *rules[1].ExpiresAt, time.Second)
+		}
+		assert.Equal(t, "carol", rules[2].V0)
+		assert.NotNil(t, rules[2].ValidFrom)
+	}
+}
+
+func TestValidityBounds(t *testing.T) {
+	db := openSqliteTestDB(t)
+	a, err := NewAdapterByDBWithOptions(db, "", "", WithValidity(), WithAutoMigrate())
+	assert.NoError(t, err)
+	now := time.Now()
+	assert.ErrorIs(t, a.AddPolicyFor("p", "p", []string{"alice", "data1", "read"}, -time.Hour), ErrInvalidValidity)
+	assert.ErrorIs(t, a.AddPolicyBetween("p", "p", []string{"alice", "data1", "read"}, now, now), ErrInvalidValidity)
+	assert.ErrorIs(t, a.AddPolicyBetween("p", "p", []string{"alice", "data1", "read"}, now, now.Add(-time.Hour)), ErrInvalidValidity)
+}
+
+func TestValidityReplacesInvalidCopies(t *testing.T) {
+	db := openSqliteTestDB(t)
+	a, err := NewAdapterByDBWithOptions(db, "", "", WithValidity(), WithAutoMigrate())
+	assert.NoError(t, err)
+	past := time.Now().Add(-time.Minute)
+	for _, user := range []string{"alice", "bob", "carol"} {
+		assert.NoError(t, a.AddPolicyUntil("p", "p", []string{user, "data1", "read"}, past))
+	}
+
+	// An expired rule that is not purged yet does not block adding it again.
+	assert.NoError(t, a.AddPolicy("p", "p", []string{"alice", "data1", "read"}))
+	assert.NoError(t, a.AddPolicyFor("p", "p", []string{"bob", "data1", "read"}, time.Hour))
+
+	// Nor does it make SavePolicy lose a rule granted in the model.
+	e, err := casbin.NewEnforcer("examples/rbac_model.conf", a)
+	assert.NoError(t, err)
+	e.EnableAutoSave(false)
+	_, err = e.AddPolicy("carol", "data1", "read")
+	assert.NoError(t, err)
+	assert.NoError(t, e.SavePolicy())
+	assert.NoError(t, e.LoadPolicy())
+	testGetPolicy(t, e, [][]string{{"alice", "data1", "read"}, {"bob", "data1", "read"}, {"carol", "data1", "read"}})
+
+	var count int64
+	assert.NoError(t, db.Table("casbin_rule").Count(&count).Error)
+	assert.Equal(t, int64(3), count)
+}
@@ -0,0 +1,202 @@
+// Copyright 2025 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gormadapter
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+const writeGuardName = "casbin:write_guard"
+
+// The operations checked by a WriteGuard, which are also the default actions.
+const (
+	OperationCreate = "create"
+	OperationUpdate = "update"
+	OperationDelete = "delete"
+)
+
+// ErrForbidden is matched by the errors of the statements a WriteGuard aborts.
+var ErrForbidden = errors.New("[casbin] gorm adapter error: forbidden")
+
+// ForbiddenError is the error of a statement aborted by a WriteGuard, it matches ErrForbidden.
+type ForbiddenError struct {
+	Subject string
+	Object  string
+	Action  string
+}
+
+func (e *ForbiddenError) Error() string {
+	return fmt.Sprintf("%v: %s may not %s %s", ErrForbidden, e.Subject, e.Action, e.Object)
+}
+
+// Is reports whether target is ErrForbidden.
+func (e *ForbiddenError) Is(target error) bool {
+	return target == ErrForbidden
+}
+
+// RequestEnforcer is the part of the casbin enforcer the WriteGuard needs, e.g. *casbin.Enforcer.
+type RequestEnforcer interface {
+	Enforce(rvals ...interface{}) (bool, error)
+}
+
+// GuardedModel configures the checks of a model.
+type GuardedModel struct {
+	Model interface{}
+	// Object is the object to check, by default the one of WriteGuardConfig.Object.
+	Object string
+	// Operations are the operations to check, all of them by default.
+	Operations []string
+}
+
+// WriteGuardConfig configures a WriteGuard.
+type WriteGuardConfig struct {
+	// Enforcer checks the requests.
+	Enforcer RequestEnforcer
+	// Subject resolves the subject from the statement context.
+	Subject SubjectResolver
+	// Models are the checked models.
+	Models []GuardedModel
+	// AllModels checks every statement, not only those of Models.
+	AllModels bool
+	// Object returns the object of a statement, its table name by default.
+	Object func(stmt *gorm.Statement) string
+	// Actions maps the operations to actions, an operation is its own action by default.
+	Actions map[string]string
+}
+
+// WriteGuard is a gorm plugin that enforces the request (subject, object, action) before the
+// Create, Update and Delete statements of the guarded models. A statement that is not allowed is
+// aborted with a ForbiddenError before reaching the database. Raw SQL is not checked.
+//
+// Example:
+//
+//	g, _ := NewWriteGuard(WriteGuardConfig{Enforcer: e, Subject: SubjectFromContext(userKey), AllModels: true})
+//	_ = db.Use(g)
+//	err := db.WithContext(context.WithValue(ctx, userKey, "alice")).Create(&book).Error
+//	if errors.Is(err, ErrForbidden) {
+//		...
+//	}
+type WriteGuard struct {
+	config WriteGuardConfig
+	mu     sync.RWMutex
+	models map[reflect.Type]GuardedModel
+}
+
+var _ gorm.Plugin = (*WriteGuard)(nil)
+
+// NewWriteGuard returns a WriteGuard, register it with db.Use.
+func NewWriteGuard(config WriteGuardConfig) (*WriteGuard, error) {
+	if config.Enforcer == nil {
+		return nil, errors.New("write guard needs an enforcer")
+	}
+	if config.Subject == nil {
+		return nil, errors.New("write guard needs a subject resolver")
+	}
+	if config.Object == nil {
+		config.Object = func(stmt *gorm.Statement) string {
+			return stmt.Table
+		}
+	}
+	g := &WriteGuard{config: config, models: make(map[reflect.Type]GuardedModel)}
+	g.Guard(config.Models...)
+	return g, nil
+}
+
+// Guard adds models to check.
+func (g *WriteGuard) Guard(models ...GuardedModel) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, model := range models {
+		g.models[modelType(model.Model)] = model
+	}
+}
+
+// Name implements gorm.Plugin.
+func (g *WriteGuard) Name() string {
+	return writeGuardName
+}
+
+// Initialize implements gorm.Plugin.
+func (g *WriteGuard) Initialize(db *gorm.DB) error {
+	if err := db.Callback().Create().Before("*").Register(writeGuardName, g.check(OperationCreate)); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().Before("*").Register(writeGuardName, g.check(OperationUpdate)); err != nil {
+		return err
+	}
+	return db.Callback().Delete().Before("*").Register(writeGuardName, g.check(OperationDelete))
+}
+
+// object returns the object to check for operation on stmt, and false if it is not checked.
+func (g *WriteGuard) object(stmt *gorm.Statement, operation string) (string, bool) {
+	var model GuardedModel
+	ok := false
+	if stmt.Schema != nil {
+		g.mu.RLock()
+		model, ok = g.models[stmt.Schema.ModelType]
+		g.mu.RUnlock()
+	}
+	if !ok {
+		return g.config.Object(stmt), g.config.AllModels
+	}
+	if len(model.Operations) > 0 {
+		checked := false
+		for _, op := range model.Operations {
+			checked = checked || op == operation
+		}
+		if !checked {
+			return "", false
+		}
+	}
+	if model.Object != "" {
+		return model.Object, true
+	}
+	return g.config.Object(stmt), true
+}
+
+func (g *WriteGuard) check(operation string) func(db *gorm.DB) {
+	action := operation
+	if a, ok := g.config.Actions[operation]; ok {
+		action = a
+	}
+	return func(db *gorm.DB) {
+		stmt := db.Statement
+		if db.Error != nil || authorizationSkipped(stmt.Context) {
+			return
+		}
+		object, ok := g.object(stmt, operation)
+		if !ok {
+			return
+		}
+		subject, err := g.config.Subject(stmt.Context)
+		if err != nil {
+			_ = db.AddError(err)
+			return
+		}
+		allowed, err := g.config.Enforcer.Enforce(subject, object, action)
+		if err != nil {
+			_ = db.AddError(err)
+			return
+		}
+		if !allowed {
+			_ = db.AddError(&ForbiddenError{Subject: subject, Object: object, Action: action})
+		}
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2025 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gormadapter
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/anzimu/casbin/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+type Author struct {
+	ID   int
+	Name string
+}
+
+func TestWriteGuard(t *testing.T) {
+	db := initBooks(t)
+	assert.NoError(t, db.AutoMigrate(&Author{}))
+	e, err := casbin.NewEnforcer("examples/rbac_model.conf")
+	assert.NoError(t, err)
+	_, err = e.AddPolicies([][]string{
+		{"editor", "books", "create"}, {"editor", "books", "write"},
+		{"admin", "books", "delete"}, {"admin", "catalog", "write"},
+	})
+	assert.NoError(t, err)
+	_, err = e.AddGroupingPolicies([][]string{{"alice", "editor"}, {"bob", "admin"}})
+	assert.NoError(t, err)
+
+	g, err := NewWriteGuard(WriteGuardConfig{
+		Enforcer: e,
+		Subject:  SubjectFromContext(userKey{}),
+		Models:   []GuardedModel{{Model: &Book{}}},
+		Actions:  map[string]string{OperationUpdate: "write"},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, db.Use(g))
+	alice := db.WithContext(context.WithValue(context.Background(), userKey{}, "alice"))
+	bob := db.WithContext(context.WithValue(context.Background(), userKey{}, "bob"))
+
+	assert.NoError(t, alice.Create(&Book{ID: 7, Title: "book7"}).Error)
+	err = bob.Create(&Book{ID: 8, Title: "book8"}).Error
+	assert.ErrorIs(t, err, ErrForbidden)
+	var forbidden *ForbiddenError
+	if assert.True(t, errors.As(err, &forbidden)) {
+		assert.Equal(t, ForbiddenError{Subject: "bob", Object: "books", Action: "create"}, *forbidden)
+	}
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7}, bookIDs(t, db))
+
+	assert.NoError(t, alice.Model(&Book{}).Where("id = ?", 7).Update("price", 5).Error)
+	assert.ErrorIs(t, bob.Model(&Book{}).Where("id = ?", 7).Update("price", 6).Error, ErrForbidden)
+	assert.ErrorIs(t, alice.Delete(&Book{}, 7).Error, ErrForbidden)
+	assert.Equal(t, []int{7}, bookIDs(t, db.Where("price = ?", 5)))
+	assert.NoError(t, bob.Delete(&Book{}, 7).Error)
+
+	// Models that are not guarded, statements without a subject and skipped statements.
+	assert.NoError(t, bob.Create(&Author{Name: "author1"}).Error)
+	assert.ErrorIs(t, db.Delete(&Book{}, 6).Error, ErrSubjectNotFound)
+	assert.NoError(t, db.WithContext(SkipAuthorization(context.Background())).Delete(&Book{}, 6).Error)
+
+	// Per model object and operations.
+	g.Guard(GuardedModel{Model: &Book{}, Object: "catalog", Operations: []string{OperationUpdate}})
+	assert.NoError(t, bob.Model(&Book{}).Where("id = ?", 5).Update("price", 6).Error)
+	assert.ErrorIs(t, alice.Model(&Book{}).Where("id = ?", 5).Update("price", 7).Error, ErrForbidden)
+	assert.NoError(t, alice.Delete(&Book{}, 5).Error)
+	assert.Equal(t, []int{1, 2, 3, 4}, bookIDs(t, db))
+}
+
+func TestWriteGuardAllModels(t *testing.T) {
+	db := initBooks(t)
+	assert.NoError(t, db.AutoMigrate(&Author{}))
+	e, err := casbin.NewEnforcer("examples/rbac_model.conf")
+	assert.NoError(t, err)
+	_, err = e.AddPolicy("alice", "authors", "create")
+	assert.NoError(t, err)
+
+	g, err := NewWriteGuard(WriteGuardConfig{Enforcer: e, Subject: SubjectFromContext(userKey{}), AllModels: true})
+	assert.NoError(t, err)
+	assert.NoError(t, db.Use(g))
+	alice := db.WithContext(context.WithValue(context.Background(), userKey{}, "alice"))
+
+	assert.NoError(t, alice.Create(&Author{Name: "author1"}).Error)
+	assert.ErrorIs(t, alice.Create(&Book{ID: 7}).Error, ErrForbidden)
+	assert.NoError(t, alice.Table("authors").Create(map[string]interface{}{"name": "author2"}).Error)
+	assert.ErrorIs(t, alice.Table("books").Create(map[string]interface{}{"id": 8}).Error, ErrForbidden)
+}